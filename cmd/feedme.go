@@ -3,18 +3,38 @@ package main
 import (
 	"flag"
 	"log"
+	"time"
 
+	"github.com/bossley9/feedme/pkg/api"
+	"github.com/bossley9/feedme/pkg/handlers"
 	"github.com/bossley9/feedme/pkg/server"
 )
 
 func main() {
-	var domain, port, certFile, keyFile string
+	var domain, port, certFile, keyFile, validation string
+	var cacheSize int
+	var robots, utc bool
 
 	flag.StringVar(&domain, "d", "localhost", "server domain name")
 	flag.StringVar(&port, "p", "9000", "server port")
 	flag.StringVar(&certFile, "c", "", "TLS certificate file")
 	flag.StringVar(&certFile, "k", "", "TLS key file")
+	flag.StringVar(&validation, "validate", "repair", "feed validation mode: off, strict or repair")
+	flag.IntVar(&cacheSize, "cache", 256, "maximum number of cached feeds")
+	flag.BoolVar(&robots, "robots", false, "respect robots.txt of scraped pages")
+	flag.BoolVar(&utc, "utc", false, "write every feed date in UTC")
 	flag.Parse()
 
-	log.Fatal(server.New(domain, port, certFile, keyFile))
+	validationMode, err := handlers.ParseValidationMode(validation)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	handlers.SetCacheSize(cacheSize)
+	api.DefaultClient.RespectRobots = robots
+	if utc {
+		handlers.SetDateLocation(time.UTC)
+	}
+
+	log.Fatal(server.New(domain, port, certFile, keyFile, validationMode))
 }
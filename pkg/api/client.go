@@ -0,0 +1,339 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bossley9/feedme/pkg/atom"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultUserAgent identifies feedme to upstream services.
+var DefaultUserAgent = atom.NAME + "/" + atom.VERSION + " (+https://" + atom.PKG + ")"
+
+// Client fetches upstream resources politely. It identifies itself with a
+// User-Agent, revalidates resources it fetched before with conditional
+// requests, waits out rate limits and can respect robots.txt.
+type Client struct {
+	HTTPClient *http.Client
+	UserAgent  string
+
+	// RespectRobots makes GetHTML refuse pages disallowed by robots.txt.
+	// API requests made with Get are not checked.
+	RespectRobots bool
+
+	// 429 and 503 responses are retried up to MaxRetries times when their
+	// Retry-After is at most MaxRetryWait. Hosts asking to wait longer are
+	// not contacted again until then.
+	MaxRetries   int
+	MaxRetryWait time.Duration
+
+	// Responses are kept for conditional requests while their bodies total
+	// at most MaxStoredSize bytes, evicting the oldest first. Bodies larger
+	// than MaxStoredBody bytes are not kept.
+	MaxStoredSize int64
+	MaxStoredBody int64
+
+	// MaxBodySize bounds the size of response bodies in bytes.
+	MaxBodySize int64
+
+	mu           sync.Mutex
+	stored       map[string]storedResponse
+	storedOrder  []string
+	storedSize   int64
+	robots       map[string]robotsEntry
+	blockedUntil map[string]time.Time
+}
+
+// storedResponse is a previous response which is reused when the upstream
+// service reports it has not been modified.
+type storedResponse struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+func NewClient() *Client {
+	return &Client{
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+		UserAgent:     DefaultUserAgent,
+		MaxRetries:    2,
+		MaxRetryWait:  5 * time.Second,
+		MaxStoredSize: 32 << 20,
+		MaxStoredBody: 2 << 20,
+		MaxBodySize:   MaxBodySize,
+		stored:        map[string]storedResponse{},
+		robots:        map[string]robotsEntry{},
+		blockedUntil:  map[string]time.Time{},
+	}
+}
+
+// MaxBodySize is the default limit of response bodies in bytes.
+var MaxBodySize int64 = 10 << 20
+
+// BodyTooLargeError reports a response body larger than the size limit.
+type BodyTooLargeError struct {
+	URL   string
+	Limit int64
+}
+
+func (err BodyTooLargeError) Error() string {
+	return err.URL + " responded with more than the limit of " + strconv.FormatInt(err.Limit, 10) + " bytes"
+}
+
+// readBody reads at most limit bytes of body.
+func readBody(body io.Reader, limit int64, url string) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, BodyTooLargeError{url, limit}
+	}
+	return data, nil
+}
+
+// DefaultClient is used by FetchGet and FetchHTML.
+var DefaultClient = NewClient()
+
+// RateLimitError reports an upstream service which asked not to be
+// contacted again until Until.
+type RateLimitError struct {
+	Host  string
+	Until time.Time
+}
+
+func (err RateLimitError) Error() string {
+	return "rate limited by " + err.Host + " until " + err.Until.Format(time.RFC1123)
+}
+
+// Get fetches the body of url. Responses which are not successful are
+// returned as errors.
+func (c *Client) Get(rawURL string) ([]byte, error) {
+	return c.GetContext(context.Background(), rawURL)
+}
+
+// GetContext is like Get but stops fetching and waiting to retry when ctx
+// is done.
+func (c *Client) GetContext(ctx context.Context, rawURL string) ([]byte, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	host := target.Host
+
+	for attempt := 0; ; attempt++ {
+		if until, blocked := c.getBlockedUntil(host); blocked {
+			return nil, RateLimitError{host, until}
+		}
+
+		body, err := c.get(ctx, rawURL)
+		var retry retryError
+		if !errors.As(err, &retry) {
+			return body, err
+		}
+
+		// retry once the service asks to, or stop contacting it until then
+		if attempt >= c.MaxRetries || retry.after > c.MaxRetryWait {
+			until := time.Now().Add(retry.after)
+			c.setBlockedUntil(host, until)
+			return nil, RateLimitError{host, until}
+		}
+		timer := time.NewTimer(retry.after)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryError asks to retry a request once after has passed.
+type retryError struct {
+	after time.Duration
+}
+
+func (err retryError) Error() string {
+	return "retry after " + err.after.String()
+}
+
+// statusError reports a response which was not successful.
+type statusError struct {
+	url    string
+	code   int
+	status string
+}
+
+func (err statusError) Error() string {
+	return err.url + " responded with " + err.status
+}
+
+// get makes a single request. Services which are rate limiting or
+// temporarily unavailable are reported with a retryError.
+func (c *Client) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	stored, hasStored := c.getStored(rawURL)
+	if hasStored {
+		if len(stored.etag) > 0 {
+			req.Header.Set("If-None-Match", stored.etag)
+		}
+		if len(stored.lastModified) > 0 {
+			req.Header.Set("If-Modified-Since", stored.lastModified)
+		}
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && hasStored:
+		return stored.body, nil
+
+	case res.StatusCode == http.StatusTooManyRequests:
+		retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After"))
+		if !ok {
+			retryAfter = time.Second
+		}
+		return nil, retryError{retryAfter}
+
+	case res.StatusCode == http.StatusServiceUnavailable:
+		// unavailable services are only retried when they say when
+		if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return nil, retryError{retryAfter}
+		}
+
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		body, err := readBody(res.Body, c.MaxBodySize, rawURL)
+		if err != nil {
+			return nil, err
+		}
+		c.setStored(rawURL, storedResponse{
+			etag:         res.Header.Get("ETag"),
+			lastModified: res.Header.Get("Last-Modified"),
+			body:         body,
+		})
+		return body, nil
+	}
+
+	return nil, statusError{rawURL, res.StatusCode, res.Status}
+}
+
+// GetHTML fetches and parses the html document at url.
+func (c *Client) GetHTML(rawURL string) (*goquery.Document, error) {
+	return c.GetHTMLContext(context.Background(), rawURL)
+}
+
+// GetHTMLContext is like GetHTML but stops fetching when ctx is done.
+func (c *Client) GetHTMLContext(ctx context.Context, rawURL string) (*goquery.Document, error) {
+	if c.RespectRobots {
+		allowed, err := c.isAllowed(ctx, rawURL)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("fetching " + rawURL + " is disallowed by robots.txt")
+		}
+	}
+
+	res, err := c.GetContext(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return goquery.NewDocumentFromReader(bytes.NewReader(res))
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as a date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func (c *Client) getBlockedUntil(host string) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until, ok := c.blockedUntil[host]
+	if ok && time.Now().After(until) {
+		delete(c.blockedUntil, host)
+		return time.Time{}, false
+	}
+	return until, ok
+}
+
+func (c *Client) setBlockedUntil(host string, until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blockedUntil[host] = until
+}
+
+func (c *Client) getStored(rawURL string) (storedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stored, ok := c.stored[rawURL]
+	return stored, ok
+}
+
+// setStored keeps responses with validators and small enough bodies,
+// forgetting the oldest responses beyond MaxStoredSize.
+func (c *Client) setStored(rawURL string, stored storedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous, exists := c.stored[rawURL]; exists {
+		delete(c.stored, rawURL)
+		c.storedSize -= int64(len(previous.body))
+		for i, storedURL := range c.storedOrder {
+			if storedURL == rawURL {
+				c.storedOrder = append(c.storedOrder[:i], c.storedOrder[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(stored.etag) == 0 && len(stored.lastModified) == 0 {
+		return
+	}
+	size := int64(len(stored.body))
+	if size > c.MaxStoredBody || size > c.MaxStoredSize {
+		return
+	}
+	c.stored[rawURL] = stored
+	c.storedOrder = append(c.storedOrder, rawURL)
+	c.storedSize += size
+
+	for c.storedSize > c.MaxStoredSize {
+		oldest := c.storedOrder[0]
+		c.storedSize -= int64(len(c.stored[oldest].body))
+		delete(c.stored, oldest)
+		c.storedOrder = c.storedOrder[1:]
+	}
+}
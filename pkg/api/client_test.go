@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_ConditionalRequests(t *testing.T) {
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("User-Agent") != DefaultUserAgent {
+			t.Errorf("Expected User-Agent %s, got %s", DefaultUserAgent, r.Header.Get("User-Agent"))
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("feed"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	for i := 0; i < 2; i++ {
+		body, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != "feed" {
+			t.Errorf("Expected body 'feed', got '%s'", body)
+		}
+	}
+
+	if requests != 2 || notModified != 1 {
+		t.Errorf("Expected second request to be conditional, got %d requests and %d not modified", requests, notModified)
+	}
+}
+
+func TestClient_StoredSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(strings.Repeat("a", len(r.URL.Path))))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.MaxStoredSize = 9
+	client.MaxStoredBody = 6
+	for _, path := range []string{"/1234", "/5678", "/big-body"} {
+		if _, err := client.Get(server.URL + path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok := client.getStored(server.URL + "/big-body"); ok {
+		t.Error("Expected body above MaxStoredBody not to be kept")
+	}
+	if _, ok := client.getStored(server.URL + "/1234"); ok {
+		t.Error("Expected oldest response beyond MaxStoredSize to be forgotten")
+	}
+	if _, ok := client.getStored(server.URL + "/5678"); !ok {
+		t.Error("Expected newest response to be kept")
+	}
+	if client.storedSize != 5 {
+		t.Errorf("Expected 5 stored bytes, got %d", client.storedSize)
+	}
+}
+
+func TestClient_RetryAfter(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("feed"))
+	}))
+	defer server.Close()
+
+	body, err := NewClient().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "feed" || requests != 2 {
+		t.Errorf("Expected rate limited request to be retried, got '%s' after %d requests", body, requests)
+	}
+}
+
+func TestClient_RateLimited(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(server.URL)
+		rateLimitErr, ok := err.(RateLimitError)
+		if !ok {
+			t.Fatalf("Expected RateLimitError, got %v", err)
+		}
+		if time.Until(rateLimitErr.Until) < 59*time.Minute {
+			t.Errorf("Expected to wait an hour, got %s", rateLimitErr.Until)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected rate limited host not to be contacted again, got %d requests", requests)
+	}
+}
+
+func TestClient_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := NewClient().Get(server.URL); err == nil {
+		t.Error("Expected 404 response to throw error")
+	}
+}
+
+func TestClient_Robots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		w.Write([]byte("<html><title>page</title></html>"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	if _, err := client.GetHTML(server.URL + "/private/page"); err != nil {
+		t.Errorf("Expected robots.txt to be ignored by default, got %v", err)
+	}
+
+	client.RespectRobots = true
+	if _, err := client.GetHTML(server.URL + "/private/page"); err == nil {
+		t.Error("Expected disallowed page to throw error")
+	}
+	if _, err := client.GetHTML(server.URL + "/public"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_RobotsRateLimited(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.RespectRobots = true
+	for i := 0; i < 2; i++ {
+		_, err := client.GetHTML(server.URL + "/page")
+		var rateLimitErr RateLimitError
+		if !errors.As(err, &rateLimitErr) {
+			t.Fatalf("Expected RateLimitError, got %v", err)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected rate limited host not to be asked for robots.txt again, got %d requests", requests)
+	}
+}
+
+func TestClient_RobotsLimit(t *testing.T) {
+	client := NewClient()
+	start := time.Now()
+	for i := 0; i <= maxRobots; i++ {
+		client.setRobots(fmt.Sprintf("https://%d.example.com", i), robotsEntry{fetched: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	if len(client.robots) != maxRobots {
+		t.Errorf("Expected %d hosts to be kept, got %d", maxRobots, len(client.robots))
+	}
+	if _, ok := client.robots["https://0.example.com"]; ok {
+		t.Error("Expected oldest host to be forgotten")
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	robots := []byte(`# comment
+User-agent: *
+Disallow: /
+
+User-agent: other
+User-agent: Feedme
+Disallow: /tracks
+Allow: /tracks/public
+Disallow: /*.json$
+`)
+	rules := parseRobots(robots, DefaultUserAgent)
+
+	tests := map[string]bool{
+		"/":                 true,
+		"/user":             true,
+		"/tracks":           false,
+		"/tracks/1":         false,
+		"/tracks/public/1":  true,
+		"/api/data.json":    false,
+		"/api/data.json?x":  true,
+		"/api/data.jsonnet": true,
+	}
+	for path, ref := range tests {
+		if allowed := isAllowedPath(rules, path); allowed != ref {
+			t.Errorf("Expected %s to be allowed %t, got %t", path, ref, allowed)
+		}
+	}
+
+	if isAllowedPath(parseRobots(robots, "Other/1.0"), "/tracks") {
+		t.Error("Expected agents of a shared group to follow its rules")
+	}
+	if isAllowedPath(parseRobots(robots, "Unknown/1.0"), "/user") {
+		t.Error("Expected unknown agents to follow the * group")
+	}
+}
+
+func TestClient_BodyTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 11))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.MaxBodySize = 10
+	_, err := client.Get(server.URL)
+	if _, ok := err.(BodyTooLargeError); !ok {
+		t.Errorf("Expected BodyTooLargeError, got %v", err)
+	}
+
+	client.MaxBodySize = 11
+	if _, err := client.Get(server.URL); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_Context(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := NewClient().GetContext(ctx, server.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline to cancel the request, got %v", err)
+	}
+}
@@ -1,17 +1,13 @@
 package api
 
 import (
-	"io/ioutil"
-	"net/http"
+	"context"
 )
 
 func FetchGet(url string) ([]byte, error) {
-	res, err := http.Get(url)
-	if err != nil {
-		return []byte{}, err
-	}
-
-	defer res.Body.Close()
+	return DefaultClient.Get(url)
+}
 
-	return ioutil.ReadAll(res.Body)
+func FetchGetContext(ctx context.Context, url string) ([]byte, error) {
+	return DefaultClient.GetContext(ctx, url)
 }
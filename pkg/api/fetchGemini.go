@@ -6,11 +6,11 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"git.sr.ht/~adnano/go-gemini"
 	"git.sr.ht/~adnano/go-gemini/tofu"
@@ -76,11 +76,10 @@ func getInput(prompt string) (input string, ok bool) {
 	return scanner.Text(), true
 }
 
-func do(req *gemini.Request, via []*gemini.Request) (*gemini.Response, error) {
+func do(ctx context.Context, req *gemini.Request, via []*gemini.Request) (*gemini.Response, error) {
 	client := gemini.Client{
 		TrustCertificate: trustCertificate,
 	}
-	ctx := context.Background()
 	resp, err := client.Do(ctx, req)
 	if err != nil {
 		return resp, err
@@ -94,7 +93,7 @@ func do(req *gemini.Request, via []*gemini.Request) (*gemini.Response, error) {
 		}
 		req.URL.ForceQuery = true
 		req.URL.RawQuery = gemini.QueryEscape(input)
-		return do(req, via)
+		return do(ctx, req, via)
 
 	case gemini.StatusRedirect:
 		via = append(via, req)
@@ -109,18 +108,24 @@ func do(req *gemini.Request, via []*gemini.Request) (*gemini.Response, error) {
 		target = req.URL.ResolveReference(target)
 		redirect := *req
 		redirect.URL = target
-		return do(&redirect, via)
+		return do(ctx, &redirect, via)
 	}
 
 	return resp, err
 }
 
 func FetchGemini(url string) ([]byte, error) {
+	return FetchGeminiContext(context.Background(), url)
+}
+
+// FetchGeminiContext fetches a gemini resource of at most MaxBodySize bytes,
+// stopping when ctx is done.
+func FetchGeminiContext(ctx context.Context, url string) ([]byte, error) {
 	req, err := gemini.NewRequest(url)
 	if err != nil {
 		return []byte{}, err
 	}
-	res, err := do(req, nil)
+	res, err := do(ctx, req, nil)
 	if err != nil {
 		return []byte{}, err
 	}
@@ -128,9 +133,9 @@ func FetchGemini(url string) ([]byte, error) {
 	defer res.Body.Close()
 
 	if res.Status.Class() != gemini.StatusSuccess {
-		message := string(res.Status) + ": " + res.Meta
+		message := strconv.Itoa(int(res.Status)) + ": " + res.Meta
 		return []byte{}, errors.New(message)
 	}
 
-	return ioutil.ReadAll(res.Body)
+	return readBody(res.Body, MaxBodySize, url)
 }
@@ -1,16 +1,15 @@
 package api
 
 import (
-	"bytes"
+	"context"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
 func FetchHTML(url string) (*goquery.Document, error) {
-	res, err := FetchGet(url)
-	if err != nil {
-		return nil, err
-	}
+	return DefaultClient.GetHTML(url)
+}
 
-	return goquery.NewDocumentFromReader(bytes.NewReader(res))
+func FetchHTMLContext(ctx context.Context, url string) (*goquery.Document, error) {
+	return DefaultClient.GetHTMLContext(ctx, url)
 }
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// robots.txt as described in RFC 9309
+
+const robotsTTL = 24 * time.Hour
+
+// maxRobots bounds how many hosts robots.txt rules are kept for.
+const maxRobots = 256
+
+type robotsEntry struct {
+	rules   []robotsRule
+	fetched time.Time
+}
+
+type robotsRule struct {
+	allow bool
+	path  string
+}
+
+// isAllowed reports whether robots.txt of the host allows the client to
+// fetch url. Hosts without a robots.txt allow everything.
+func (c *Client) isAllowed(ctx context.Context, rawURL string) (bool, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return false, err
+	}
+	origin := target.Scheme + "://" + target.Host
+
+	c.mu.Lock()
+	entry, ok := c.robots[origin]
+	c.mu.Unlock()
+
+	if !ok || time.Since(entry.fetched) > robotsTTL {
+		rules, err := c.fetchRobots(ctx, origin)
+		if err != nil {
+			return false, err
+		}
+		entry = robotsEntry{rules, time.Now()}
+
+		c.setRobots(origin, entry)
+	}
+
+	path := target.EscapedPath()
+	if len(target.RawQuery) > 0 {
+		path += "?" + target.RawQuery
+	}
+	return isAllowedPath(entry.rules, path), nil
+}
+
+// setRobots keeps the rules of a host, forgetting expired rules and then the
+// oldest rules beyond maxRobots.
+func (c *Client) setRobots(origin string, entry robotsEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.robots[origin]; !exists && len(c.robots) >= maxRobots {
+		var oldest string
+		for cached, cachedEntry := range c.robots {
+			if time.Since(cachedEntry.fetched) > robotsTTL {
+				delete(c.robots, cached)
+			} else if len(oldest) == 0 || cachedEntry.fetched.Before(c.robots[oldest].fetched) {
+				oldest = cached
+			}
+		}
+		if len(c.robots) >= maxRobots {
+			delete(c.robots, oldest)
+		}
+	}
+	c.robots[origin] = entry
+}
+
+// fetchRobots fetches the rules of robots.txt which apply to the client.
+// Missing files allow everything while unreachable files are errors. Rate
+// limited hosts are waited for or blocked like any other request.
+func (c *Client) fetchRobots(ctx context.Context, origin string) ([]robotsRule, error) {
+	body, err := c.GetContext(ctx, origin+"/robots.txt")
+	var statusErr statusError
+	if errors.As(err, &statusErr) && statusErr.code >= 400 && statusErr.code < 500 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseRobots(body, c.UserAgent), nil
+}
+
+// parseRobots returns the rules of the groups matching the product token of
+// userAgent, or of the "*" groups when no group matches.
+func parseRobots(body []byte, userAgent string) []robotsRule {
+	product := strings.ToLower(strings.SplitN(userAgent, "/", 2)[0])
+
+	var matched, wildcard []robotsRule
+	var hasMatched bool
+	var agents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines start a single group
+			if inRules {
+				agents = nil
+				inRules = false
+			}
+			agent := strings.ToLower(value)
+			agents = append(agents, agent)
+			if agent == product {
+				hasMatched = true
+			}
+
+		case "allow", "disallow":
+			inRules = true
+			// an empty disallow allows everything
+			if len(value) == 0 {
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", path: value}
+			for _, agent := range agents {
+				if agent == product {
+					matched = append(matched, rule)
+				} else if agent == "*" {
+					wildcard = append(wildcard, rule)
+				}
+			}
+		}
+	}
+
+	if hasMatched {
+		return matched
+	}
+	return wildcard
+}
+
+// isAllowedPath applies the most specific matching rule, preferring allow
+// rules when rules are equally specific.
+func isAllowedPath(rules []robotsRule, path string) bool {
+	allowed := true
+	longest := -1
+	for _, rule := range rules {
+		if !matchRobotsPath(rule.path, path) {
+			continue
+		}
+		if len(rule.path) > longest || (len(rule.path) == longest && rule.allow) {
+			allowed = rule.allow
+			longest = len(rule.path)
+		}
+	}
+	return allowed
+}
+
+// matchRobotsPath matches path against a rule which may contain "*"
+// wildcards and end with "$".
+func matchRobotsPath(pattern string, path string) bool {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(pattern, "$")), `\*`, ".*")
+	if strings.HasSuffix(pattern, "$") {
+		expr += "$"
+	}
+	matched, _ := regexp.MatchString(expr, path)
+	return matched
+}
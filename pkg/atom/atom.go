@@ -4,14 +4,87 @@ package atom
 
 import (
 	"encoding/xml"
+	"errors"
+	"strings"
 	"time"
 )
 
+// s2
+
+// commonAttrs reads the atomCommonAttributes allowed on every element.
+// xml:lang sets the language of the element and its children, while
+// xml:base sets the base IRI used to resolve relative references within the
+// element.
+func commonAttrs(attrs []xml.Attr) (AtomLanguageTag, AtomURI) {
+	var lang AtomLanguageTag
+	var base AtomURI
+	for _, attr := range attrs {
+		if attr.Name.Space != xmlNamespace {
+			continue
+		}
+		switch attr.Name.Local {
+		case "lang":
+			lang = AtomLanguageTag(attr.Value)
+		case "base":
+			base = AtomURI(attr.Value)
+		}
+	}
+	return lang, base
+}
+
 // s3.1
 
 type AtomTextConstruct struct {
-	Type AtomTextType `xml:"type,attr,omitempty"`
-	Text string       `xml:",chardata"` // required
+	Type AtomTextType    `xml:"type,attr,omitempty"`
+	Lang AtomLanguageTag `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Base AtomURI         `xml:"http://www.w3.org/XML/1998/namespace base,attr,omitempty"`
+	Text string          `xml:",chardata"` // required
+}
+
+func (text AtomTextConstruct) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if text.Type != "xhtml" {
+		type plainText AtomTextConstruct
+		return e.EncodeElement(plainText(text), start)
+	}
+
+	// xhtml markup is written as-is within a div element
+	raw := struct {
+		Type  AtomTextType    `xml:"type,attr"`
+		Lang  AtomLanguageTag `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+		Base  AtomURI         `xml:"http://www.w3.org/XML/1998/namespace base,attr,omitempty"`
+		Inner string          `xml:",innerxml"`
+	}{
+		Type:  text.Type,
+		Lang:  text.Lang,
+		Base:  text.Base,
+		Inner: wrapXHTML(text.Text),
+	}
+	return e.EncodeElement(raw, start)
+}
+
+func (text *AtomTextConstruct) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var raw struct {
+		Type AtomTextType `xml:"type,attr"`
+		Text string       `xml:",chardata"`
+		Div  *struct {
+			Inner string `xml:",innerxml"`
+		} `xml:"http://www.w3.org/1999/xhtml div"`
+	}
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+
+	text.Type = raw.Type
+	text.Lang, text.Base = commonAttrs(start.Attr)
+	text.Text = raw.Text
+	// xhtml constructs contain the markup of a single div element
+	if raw.Type == "xhtml" {
+		text.Text = ""
+		if raw.Div != nil {
+			text.Text = strings.TrimSpace(raw.Div.Inner)
+		}
+	}
+	return nil
 }
 
 // s3.1.1
@@ -42,20 +115,76 @@ type AtomEmail string // email address as defined in RFC 2822
 
 type AtomDate time.Time // datetime reference as defined in RFC 3339
 
+// Dates are written with their original offset, see String.
 func (date AtomDate) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-	str := time.Time(date).Format(time.RFC3339)
-	e.EncodeElement(str, start)
+	return e.EncodeElement(date.String(), start)
+}
+
+func (date *AtomDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var str string
+	if err := d.DecodeElement(&str, &start); err != nil {
+		return err
+	}
+
+	datetime, err := time.Parse(time.RFC3339, strings.TrimSpace(str))
+	if err != nil {
+		return FeedError("date constructs must conform to the date-time production in RFC 3339", "3.3")
+	}
+	*date = AtomDate(datetime)
 	return nil
 }
 
+// String formats the date as RFC 3339 with its original offset. Fractional
+// seconds are only written when the date has them.
+func (date AtomDate) String() string {
+	datetime := time.Time(date)
+	if datetime.Nanosecond() == 0 {
+		return datetime.Format(time.RFC3339)
+	}
+	return datetime.Format(time.RFC3339Nano)
+}
+
+// In returns the same instant in the given location.
+func (date AtomDate) In(loc *time.Location) AtomDate {
+	return AtomDate(time.Time(date).In(loc))
+}
+
+func (date AtomDate) Before(other AtomDate) bool {
+	return time.Time(date).Before(time.Time(other))
+}
+
+func (date AtomDate) After(other AtomDate) bool {
+	return time.Time(date).After(time.Time(other))
+}
+
+// Equal reports whether both dates are the same instant, even when written
+// with different offsets.
+func (date AtomDate) Equal(other AtomDate) bool {
+	return time.Time(date).Equal(time.Time(other))
+}
+
+// Compare returns -1 if the date is before other, +1 if it is after, and 0
+// if both are the same instant.
+func (date AtomDate) Compare(other AtomDate) int {
+	switch {
+	case date.Before(other):
+		return -1
+	case date.After(other):
+		return 1
+	}
+	return 0
+}
+
 // s4.1.1
 
 type AtomFeed struct {
 	XMLName      xml.Name          `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang         AtomLanguageTag   `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Base         AtomURI           `xml:"http://www.w3.org/XML/1998/namespace base,attr,omitempty"`
 	Authors      []AtomAuthor      `xml:"author"` // >1 required
 	Categories   []AtomCategory    `xml:"category"`
 	Contributors []AtomContributor `xml:"contributor"`
-	Generator    AtomGenerator     `xml:",omitempty"`
+	Generator    AtomGenerator     `xml:"generator"`
 	Icon         AtomIcon          `xml:"icon,omitempty"` // should use aspect ratio 1(h):1(v)
 	Id           AtomID            `xml:"id"`             // required
 	Links        []AtomLink        `xml:"link"`
@@ -64,13 +193,89 @@ type AtomFeed struct {
 	Subtitle     *AtomSubtitle     `xml:"subtitle,omitempty"`
 	Title        AtomTitle         `xml:"title"`   // required
 	Updated      AtomDate          `xml:"updated"` // required
+	Extensions   []AtomExtension   `xml:",any"`
 	Entries      []AtomEntry       `xml:"entry"`
 }
 
+func (feed AtomFeed) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Space: AtomNamespace, Local: "feed"}
+	// prefixes of extension elements are declared once on the root element
+	start.Attr = append(start.Attr, feed.namespaceAttrs()...)
+
+	type plainFeed AtomFeed
+	return e.EncodeElement(plainFeed(feed), start)
+}
+
+func (feed *AtomFeed) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if start.Name.Space != AtomNamespace || start.Name.Local != "feed" {
+		return errors.New("expected element <feed> in namespace " + AtomNamespace)
+	}
+	feed.XMLName = start.Name
+	feed.Lang, feed.Base = commonAttrs(start.Attr)
+
+	// atom elements are matched by name only when decoding into a struct,
+	// so children are decoded one by one to keep extension elements apart
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isExtension(child.Name) {
+			var ext AtomExtension
+			err := d.DecodeElement(&ext, &child)
+			feed.Extensions = append(feed.Extensions, ext)
+			return err
+		}
+
+		switch child.Name.Local {
+		case "author":
+			var author AtomAuthor
+			err := d.DecodeElement(&author, &child)
+			feed.Authors = append(feed.Authors, author)
+			return err
+		case "category":
+			var cat AtomCategory
+			err := d.DecodeElement(&cat, &child)
+			feed.Categories = append(feed.Categories, cat)
+			return err
+		case "contributor":
+			var contrib AtomContributor
+			err := d.DecodeElement(&contrib, &child)
+			feed.Contributors = append(feed.Contributors, contrib)
+			return err
+		case "generator":
+			return d.DecodeElement(&feed.Generator, &child)
+		case "icon":
+			return d.DecodeElement(&feed.Icon, &child)
+		case "id":
+			return d.DecodeElement(&feed.Id, &child)
+		case "link":
+			var link AtomLink
+			err := d.DecodeElement(&link, &child)
+			feed.Links = append(feed.Links, link)
+			return err
+		case "logo":
+			return d.DecodeElement(&feed.Logo, &child)
+		case "rights":
+			return d.DecodeElement(&feed.Rights, &child)
+		case "subtitle":
+			return d.DecodeElement(&feed.Subtitle, &child)
+		case "title":
+			return d.DecodeElement(&feed.Title, &child)
+		case "updated":
+			return d.DecodeElement(&feed.Updated, &child)
+		case "entry":
+			var entry AtomEntry
+			err := d.DecodeElement(&entry, &child)
+			feed.Entries = append(feed.Entries, entry)
+			return err
+		}
+		return d.Skip()
+	})
+}
+
 // s4.1.2
 
 type AtomEntry struct {
 	XMLName      xml.Name          `xml:"entry"`
+	Lang         AtomLanguageTag   `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Base         AtomURI           `xml:"http://www.w3.org/XML/1998/namespace base,attr,omitempty"`
 	Authors      []AtomAuthor      `xml:"author"`
 	Categories   []AtomCategory    `xml:"category"`
 	Content      *AtomContent      `xml:"content"`
@@ -83,14 +288,112 @@ type AtomEntry struct {
 	Summary      *AtomSummary      `xml:"summary,omitempty"`
 	Title        AtomTitle         `xml:"title"`   // required
 	Updated      AtomDate          `xml:"updated"` // required
+	Extensions   []AtomExtension   `xml:",any"`
+}
+
+func (entry *AtomEntry) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	entry.XMLName = start.Name
+	entry.Lang, entry.Base = commonAttrs(start.Attr)
+
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if isExtension(child.Name) {
+			var ext AtomExtension
+			err := d.DecodeElement(&ext, &child)
+			entry.Extensions = append(entry.Extensions, ext)
+			return err
+		}
+
+		switch child.Name.Local {
+		case "author":
+			var author AtomAuthor
+			err := d.DecodeElement(&author, &child)
+			entry.Authors = append(entry.Authors, author)
+			return err
+		case "category":
+			var cat AtomCategory
+			err := d.DecodeElement(&cat, &child)
+			entry.Categories = append(entry.Categories, cat)
+			return err
+		case "content":
+			return d.DecodeElement(&entry.Content, &child)
+		case "contributor":
+			var contrib AtomContributor
+			err := d.DecodeElement(&contrib, &child)
+			entry.Contributors = append(entry.Contributors, contrib)
+			return err
+		case "id":
+			return d.DecodeElement(&entry.Id, &child)
+		case "link":
+			var link AtomLink
+			err := d.DecodeElement(&link, &child)
+			entry.Links = append(entry.Links, link)
+			return err
+		case "published":
+			return d.DecodeElement(&entry.Published, &child)
+		case "rights":
+			return d.DecodeElement(&entry.Rights, &child)
+		case "source":
+			return d.DecodeElement(&entry.Source, &child)
+		case "summary":
+			return d.DecodeElement(&entry.Summary, &child)
+		case "title":
+			return d.DecodeElement(&entry.Title, &child)
+		case "updated":
+			return d.DecodeElement(&entry.Updated, &child)
+		}
+		return d.Skip()
+	})
 }
 
 // s4.1.2
 
 type AtomContent struct {
-	Type string  `xml:"type,attr,omitempty"` // one of "text", "html", "xhtml", or a MIME media type - default "text"
-	Src  AtomURI `xml:"src,attr,omitempty"`
-	Text string  `xml:",chardata"`
+	Type string          `xml:"type,attr,omitempty"` // one of "text", "html", "xhtml", or a MIME media type - default "text"
+	Src  AtomURI         `xml:"src,attr,omitempty"`
+	Lang AtomLanguageTag `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Base AtomURI         `xml:"http://www.w3.org/XML/1998/namespace base,attr,omitempty"`
+	Text string          `xml:",chardata"`
+}
+
+func (content AtomContent) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if content.Type != "xhtml" {
+		type plainContent AtomContent
+		return e.EncodeElement(plainContent(content), start)
+	}
+
+	// xhtml markup is written as-is within a div element
+	raw := struct {
+		Type  string          `xml:"type,attr"`
+		Src   AtomURI         `xml:"src,attr,omitempty"`
+		Lang  AtomLanguageTag `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+		Base  AtomURI         `xml:"http://www.w3.org/XML/1998/namespace base,attr,omitempty"`
+		Inner string          `xml:",innerxml"`
+	}{
+		Type:  content.Type,
+		Src:   content.Src,
+		Lang:  content.Lang,
+		Base:  content.Base,
+		Inner: wrapXHTML(content.Text),
+	}
+	return e.EncodeElement(raw, start)
+}
+
+func (content *AtomContent) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var text AtomTextConstruct
+	if err := text.UnmarshalXML(d, start); err != nil {
+		return err
+	}
+
+	content.Type = string(text.Type)
+	content.Lang = text.Lang
+	content.Base = text.Base
+	content.Text = text.Text
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "src" {
+			content.Src = AtomURI(attr.Value)
+		}
+	}
+	return nil
 }
 
 // s4.2.1
@@ -119,24 +422,57 @@ type AtomGenerator struct {
 	Text    string  `xml:",innerxml"`
 }
 
+// defaultGenerator identifies this package as the generator of the feeds it
+// creates.
+func defaultGenerator() AtomGenerator {
+	return AtomGenerator{
+		Uri:     PKG,
+		Version: VERSION,
+		Text:    "Generated via " + NAME,
+	}
+}
+
 func (generator AtomGenerator) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
-	uri := xml.Attr{
-		Name:  xml.Name{Local: "uri"},
-		Value: PKG,
+	// parsed feeds without a generator are written without one
+	if len(generator.Text) == 0 {
+		return nil
 	}
 
-	version := xml.Attr{
-		Name:  xml.Name{Local: "version"},
-		Value: VERSION,
+	var attrs []xml.Attr
+	if len(generator.Uri) > 0 {
+		attrs = append(attrs, xml.Attr{
+			Name:  xml.Name{Local: "uri"},
+			Value: string(generator.Uri),
+		})
+	}
+	if len(generator.Version) > 0 {
+		attrs = append(attrs, xml.Attr{
+			Name:  xml.Name{Local: "version"},
+			Value: generator.Version,
+		})
 	}
 
 	element := xml.StartElement{
 		Name: xml.Name{Local: "generator"},
-		Attr: []xml.Attr{uri, version},
+		Attr: attrs,
 	}
 
-	e.EncodeElement("Generated via "+NAME, element)
+	return e.EncodeElement(generator.Text, element)
+}
+
+func (generator *AtomGenerator) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var raw struct {
+		Uri     AtomURI `xml:"uri,attr"`
+		Version string  `xml:"version,attr"`
+		Text    string  `xml:",chardata"`
+	}
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
 
+	generator.Uri = raw.Uri
+	generator.Version = raw.Version
+	generator.Text = strings.TrimSpace(raw.Text)
 	return nil
 }
 
@@ -148,7 +484,8 @@ type AtomIcon AtomURI
 
 // atom:id elements must ALWAYS assure uniqueness.
 // See https://datatracker.ietf.org/doc/html/rfc4287#section-4.2.6 for
-// strategies on generating unique id elements.
+// strategies on generating unique id elements, or use TagURI and NewUUIDv5
+// to derive ids from immutable identifiers.
 type AtomID AtomURI
 
 // s4.2.7
@@ -162,40 +499,57 @@ type AtomLink struct {
 	HrefLang AtomLanguageTag `xml:"hreflang,attr,omitempty"`
 	Title    string          `xml:"title,attr,omitempty"` // HTML-escaped
 	Length   uint            `xml:"length,attr,omitempty"`
+	Attrs    []xml.Attr      `xml:",any,attr"` // extension attributes
+}
+
+func (link AtomLink) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "link"}
+	type plainLink AtomLink
+	plain := plainLink(link)
+	plain.Attrs = prefixAttrs(link.Attrs)
+	return e.EncodeElement(plain, start)
+}
+
+func (link *AtomLink) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plainLink AtomLink
+	var plain plainLink
+	if err := d.DecodeElement(&plain, &start); err != nil {
+		return err
+	}
+	plain.Attrs = withoutNamespaceDecls(plain.Attrs)
+	*link = AtomLink(plain)
+	return nil
 }
 
 // s4.2.7.2
 
-type AtomRelType int
+// either a registered relation name or an IRI
+type AtomRelType string
+
+// links without a rel attribute are alternate links
+const RelUnknown AtomRelType = ""
 
+// relations registered in the IANA link relations registry
 const (
-	RelUnknown AtomRelType = iota
-	RelAlternate
-	RelRelated
-	RelSelf
-	RelEnclosure
-	RelVia
+	RelAlternate   AtomRelType = "alternate"
+	RelRelated     AtomRelType = "related"
+	RelSelf        AtomRelType = "self"
+	RelEnclosure   AtomRelType = "enclosure"
+	RelVia         AtomRelType = "via"
+	RelFirst       AtomRelType = "first"
+	RelLast        AtomRelType = "last"
+	RelNext        AtomRelType = "next"
+	RelPrevious    AtomRelType = "previous"
+	RelCurrent     AtomRelType = "current"
+	RelPrevArchive AtomRelType = "prev-archive"
+	RelNextArchive AtomRelType = "next-archive"
+	RelReplies     AtomRelType = "replies"
+	RelPayment     AtomRelType = "payment"
+	RelHub         AtomRelType = "hub"
+	RelLicense     AtomRelType = "license"
+	RelEdit        AtomRelType = "edit"
 )
 
-func (rel AtomRelType) MarshalText() ([]byte, error) {
-	var str string
-	switch rel {
-	case RelAlternate:
-		str = "alternate"
-	case RelRelated:
-		str = "related"
-	case RelSelf:
-		str = "self"
-	case RelEnclosure:
-		str = "enclosure"
-	case RelVia:
-		str = "via"
-	default:
-		str = ""
-	}
-	return []byte(str), nil
-}
-
 // s4.2.7.3
 
 type AtomMediaType string // MIME media type
@@ -212,19 +566,65 @@ type AtomLogo AtomURI
 
 type AtomRights AtomTextConstruct
 
+func (rights AtomRights) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return AtomTextConstruct(rights).MarshalXML(e, start)
+}
+
+func (rights *AtomRights) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return (*AtomTextConstruct)(rights).UnmarshalXML(d, start)
+}
+
 // s4.2.11
 
-// unimplemented due to language constraints
-type AtomSource struct{} // extension of atom:feed without entries
+// metadata of the atom:feed an entry was copied from, without entries
+type AtomSource struct {
+	XMLName      xml.Name          `xml:"source"`
+	Authors      []AtomAuthor      `xml:"author"`
+	Categories   []AtomCategory    `xml:"category"`
+	Contributors []AtomContributor `xml:"contributor"`
+	Generator    *AtomGenerator    `xml:"generator,omitempty"`
+	Icon         AtomIcon          `xml:"icon,omitempty"`
+	Id           AtomID            `xml:"id,omitempty"` // should be present if the source feed has one
+	Links        []AtomLink        `xml:"link"`
+	Logo         AtomLogo          `xml:"logo,omitempty"`
+	Rights       *AtomRights       `xml:"rights,omitempty"`
+	Subtitle     *AtomSubtitle     `xml:"subtitle,omitempty"`
+	Title        *AtomTitle        `xml:"title,omitempty"`   // should be present if the source feed has one
+	Updated      *AtomDate         `xml:"updated,omitempty"` // should be present if the source feed has one
+}
 
 // s4.2.12
 
 type AtomSubtitle AtomTextConstruct
 
+func (subtitle AtomSubtitle) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return AtomTextConstruct(subtitle).MarshalXML(e, start)
+}
+
+func (subtitle *AtomSubtitle) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return (*AtomTextConstruct)(subtitle).UnmarshalXML(d, start)
+}
+
 // s4.2.13
 
 type AtomSummary AtomTextConstruct
 
+func (summary AtomSummary) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return AtomTextConstruct(summary).MarshalXML(e, start)
+}
+
+func (summary *AtomSummary) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return (*AtomTextConstruct)(summary).UnmarshalXML(d, start)
+}
+
 // s4.2.14
 
 type AtomTitle AtomTextConstruct
+
+func (title AtomTitle) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return AtomTextConstruct(title).MarshalXML(e, start)
+}
+
+func (title *AtomTitle) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return (*AtomTextConstruct)(title).UnmarshalXML(d, start)
+}
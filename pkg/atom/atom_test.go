@@ -40,6 +40,22 @@ func TestAtomTextConstruct_MarshalType(t *testing.T) {
 	assertEqual(t, test, ref)
 }
 
+func TestAtomTextConstruct_MarshalXhtml(t *testing.T) {
+	text := AtomTextConstruct{
+		Type: "xhtml",
+		Text: "<p>Hello &amp; <em>world</em></p>",
+	}
+	ref := `<AtomTextConstruct type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hello &amp; <em>world</em></p></div></AtomTextConstruct>`
+
+	out, err := xml.MarshalIndent(text, "", "  ")
+	if err != nil {
+		t.Error("Error: unable to marshal xml")
+	}
+	test := string(out)
+
+	assertEqual(t, test, ref)
+}
+
 // s3.2
 
 func TestAtomPersonConstruct_MarshalElements(t *testing.T) {
@@ -100,6 +116,49 @@ func TestAtomDateConstruct_Format(t *testing.T) {
 	assertEqual(t, test, ref)
 }
 
+func TestAtomDateConstruct_Precision(t *testing.T) {
+	loc := time.FixedZone("", -7*60*60)
+	date := AtomDate(time.Date(2022, 7, 4, 12, 34, 56, 789000000, loc))
+	ref := "<AtomDate>2022-07-04T12:34:56.789-07:00</AtomDate>"
+
+	out, err := xml.Marshal(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, string(out), ref)
+
+	var parsed AtomDate
+	if err := xml.Unmarshal([]byte(ref), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, parsed.String(), "2022-07-04T12:34:56.789-07:00")
+}
+
+func TestAtomDateConstruct_WholeSeconds(t *testing.T) {
+	loc := time.FixedZone("", 2*60*60)
+	date := AtomDate(time.Date(2022, 7, 4, 12, 34, 0, 0, loc))
+
+	assertEqual(t, date.String(), time.Time(date).Format(time.RFC3339))
+	assertEqual(t, date.String(), "2022-07-04T12:34:00+02:00")
+}
+
+func TestAtomDateConstruct_Compare(t *testing.T) {
+	date := AtomDate(time.Date(2022, 7, 4, 12, 0, 0, 0, time.UTC))
+	sameInstant := AtomDate(time.Date(2022, 7, 4, 5, 0, 0, 0, time.FixedZone("", -7*60*60)))
+	later := AtomDate(time.Date(2022, 7, 4, 12, 0, 0, 1, time.UTC))
+
+	if !date.Equal(sameInstant) || date.Compare(sameInstant) != 0 {
+		t.Error("Expected dates with different offsets to be equal")
+	}
+	if !date.Before(later) || date.Compare(later) != -1 {
+		t.Error("Expected date to be before a nanosecond later")
+	}
+	if !later.After(date) || later.Compare(date) != 1 {
+		t.Error("Expected date to be after a nanosecond earlier")
+	}
+	assertEqual(t, sameInstant.In(time.UTC).String(), "2022-07-04T12:00:00Z")
+}
+
 // s4.1.1
 
 func TestAtomFeed_Format(t *testing.T) {
@@ -108,7 +167,8 @@ func TestAtomFeed_Format(t *testing.T) {
 		t.Error("Error: unable parse datetime")
 	}
 	feed := AtomFeed{
-		Id: "example.com",
+		Generator: defaultGenerator(),
+		Id:        "example.com",
 		Title: AtomTitle{
 			Text: "How I Generate Atom Feeds",
 		},
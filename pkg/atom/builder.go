@@ -0,0 +1,337 @@
+package atom
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BuildError lists every error encountered while building a feed or entry.
+type BuildError struct {
+	Errors []error
+}
+
+func (err BuildError) Error() string {
+	messages := make([]string, len(err.Errors))
+	for i, e := range err.Errors {
+		messages[i] = e.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+type errorCollector struct {
+	errs []error
+}
+
+func (c *errorCollector) check(err error) {
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+// result returns the collected errors followed by the first violated
+// requirement of the report, if any.
+func (c *errorCollector) result(report ValidationReport) error {
+	errs := c.errs
+	for _, issue := range report.Issues {
+		if issue.Severity == SeverityMust {
+			errs = append(errs, issue)
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return BuildError{errs}
+}
+
+// FeedBuilder creates a feed with chained calls. Errors are collected
+// instead of returned by each call and are reported by Build.
+type FeedBuilder struct {
+	feed *AtomFeed
+	errorCollector
+}
+
+func NewFeedBuilder(id string, title string, updated time.Time) *FeedBuilder {
+	builder := FeedBuilder{feed: &AtomFeed{}}
+
+	feed, err := CreateFeed(id, title, updated)
+	builder.check(err)
+	if feed != nil {
+		builder.feed = feed
+	}
+	return &builder
+}
+
+func (b *FeedBuilder) Author(name string, uri string, email string) *FeedBuilder {
+	b.check(b.feed.AddAuthor(name, uri, email))
+	return b
+}
+
+func (b *FeedBuilder) Base(base string) *FeedBuilder {
+	b.check(b.feed.SetBase(base))
+	return b
+}
+
+func (b *FeedBuilder) Category(term string, scheme string, label string) *FeedBuilder {
+	b.check(b.feed.AddCategory(term, scheme, label))
+	return b
+}
+
+func (b *FeedBuilder) Contributor(name string, uri string, email string) *FeedBuilder {
+	b.check(b.feed.AddContributor(name, uri, email))
+	return b
+}
+
+func (b *FeedBuilder) Copyright(text string, textType string) *FeedBuilder {
+	b.check(b.feed.SetCopyright(text, textType))
+	return b
+}
+
+// Complete marks the feed as containing every entry of the feed.
+func (b *FeedBuilder) Complete() *FeedBuilder {
+	b.check(b.feed.SetComplete())
+	return b
+}
+
+func (b *FeedBuilder) Extension(ext AtomExtension) *FeedBuilder {
+	b.check(b.feed.AddExtension(ext))
+	return b
+}
+
+func (b *FeedBuilder) Icon(uri string) *FeedBuilder {
+	b.check(b.feed.SetIcon(uri))
+	return b
+}
+
+func (b *FeedBuilder) Language(lang string) *FeedBuilder {
+	b.check(b.feed.SetLanguage(lang))
+	return b
+}
+
+func (b *FeedBuilder) Link(href string, rel AtomRelType) *FeedBuilder {
+	b.check(b.feed.AddLink(href, rel))
+	return b
+}
+
+func (b *FeedBuilder) LinkWithOptions(href string, rel AtomRelType, options LinkOptions) *FeedBuilder {
+	b.check(b.feed.AddLinkWithOptions(href, rel, options))
+	return b
+}
+
+func (b *FeedBuilder) Logo(uri string) *FeedBuilder {
+	b.check(b.feed.SetLogo(uri))
+	return b
+}
+
+func (b *FeedBuilder) PageLinks(links PageLinks) *FeedBuilder {
+	b.check(b.feed.SetPageLinks(links))
+	return b
+}
+
+func (b *FeedBuilder) Subtitle(text string, textType string) *FeedBuilder {
+	b.check(b.feed.SetSubtitle(text, textType))
+	return b
+}
+
+func (b *FeedBuilder) Title(text string, textType string) *FeedBuilder {
+	b.check(b.feed.SetTitle(text, textType))
+	return b
+}
+
+// Entry adds an entry created with an EntryBuilder or CreateFeedEntry.
+func (b *FeedBuilder) Entry(entry *AtomEntry) *FeedBuilder {
+	b.check(b.feed.AddEntry(entry))
+	return b
+}
+
+// Build fills in defaults and validates the feed. Feeds are given this
+// package as generator, the id as self link when it is a web address, and
+// the title as author when an entry has no author to fall back on.
+// Recommendations are not enforced; use Report on the feed to list them.
+func (b *FeedBuilder) Build() (*AtomFeed, error) {
+	b.fillDefaults()
+	if err := b.result(b.feed.Report()); err != nil {
+		return nil, err
+	}
+	return b.feed, nil
+}
+
+// BuildUnvalidated fills in defaults like Build but only reports errors of
+// the chained calls, leaving the feed to be validated or repaired by the
+// caller.
+func (b *FeedBuilder) BuildUnvalidated() (*AtomFeed, error) {
+	b.fillDefaults()
+	if err := b.result(ValidationReport{}); err != nil {
+		return nil, err
+	}
+	return b.feed, nil
+}
+
+// Repair fills in defaults like Build and repairs the feed built so far with
+// AtomFeed.Repair, so that Build only rejects problems which cannot be
+// repaired. It returns the repairs made.
+func (b *FeedBuilder) Repair() []string {
+	b.fillDefaults()
+	return b.feed.Repair()
+}
+
+func (b *FeedBuilder) fillDefaults() {
+	feed := b.feed
+
+	if len(feed.Generator.Text) == 0 {
+		feed.Generator = defaultGenerator()
+	}
+
+	if !hasLink(feed.Links, RelSelf) && isWebAddress(string(feed.Id)) {
+		feed.AddLink(string(feed.Id), RelSelf)
+	}
+
+	if len(feed.Authors) == 0 && needsFeedAuthor(feed.Entries) {
+		feed.AddAuthor(AtomTextConstruct(feed.Title).PlainText(), "", "")
+	}
+}
+
+func hasLink(links []AtomLink, rel AtomRelType) bool {
+	for _, link := range links {
+		if link.Rel == rel {
+			return true
+		}
+	}
+	return false
+}
+
+func isWebAddress(iri string) bool {
+	address, err := url.Parse(iri)
+	return err == nil && (address.Scheme == "http" || address.Scheme == "https")
+}
+
+func needsFeedAuthor(entries []AtomEntry) bool {
+	if len(entries) == 0 {
+		return true
+	}
+	for _, entry := range entries {
+		if len(entry.Authors) == 0 && (entry.Source == nil || len(entry.Source.Authors) == 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// EntryBuilder creates an entry with chained calls. Errors are collected
+// instead of returned by each call and are reported by Build.
+type EntryBuilder struct {
+	entry *AtomEntry
+	errorCollector
+}
+
+func NewEntryBuilder(id string, title string, updated time.Time) *EntryBuilder {
+	builder := EntryBuilder{entry: &AtomEntry{}}
+
+	entry, err := CreateFeedEntry(id, title, updated)
+	builder.check(err)
+	if entry != nil {
+		builder.entry = entry
+	}
+	return &builder
+}
+
+func (b *EntryBuilder) Author(name string, uri string, email string) *EntryBuilder {
+	b.check(b.entry.AddAuthor(name, uri, email))
+	return b
+}
+
+func (b *EntryBuilder) Base(base string) *EntryBuilder {
+	b.check(b.entry.SetBase(base))
+	return b
+}
+
+func (b *EntryBuilder) Category(term string, scheme string, label string) *EntryBuilder {
+	b.check(b.entry.AddCategory(term, scheme, label))
+	return b
+}
+
+func (b *EntryBuilder) Content(content string, contentType string) *EntryBuilder {
+	b.check(b.entry.SetContent(content, contentType))
+	return b
+}
+
+func (b *EntryBuilder) Contributor(name string, uri string, email string) *EntryBuilder {
+	b.check(b.entry.AddContributor(name, uri, email))
+	return b
+}
+
+func (b *EntryBuilder) Copyright(text string, textType string) *EntryBuilder {
+	b.check(b.entry.SetCopyright(text, textType))
+	return b
+}
+
+func (b *EntryBuilder) Extension(ext AtomExtension) *EntryBuilder {
+	b.check(b.entry.AddExtension(ext))
+	return b
+}
+
+func (b *EntryBuilder) InReplyTo(ref string, href string, mediaType string, source string) *EntryBuilder {
+	b.check(b.entry.AddInReplyTo(ref, href, mediaType, source))
+	return b
+}
+
+func (b *EntryBuilder) Language(lang string) *EntryBuilder {
+	b.check(b.entry.SetLanguage(lang))
+	return b
+}
+
+func (b *EntryBuilder) Link(href string, rel AtomRelType) *EntryBuilder {
+	b.check(b.entry.AddLink(href, rel))
+	return b
+}
+
+func (b *EntryBuilder) LinkWithOptions(href string, rel AtomRelType, options LinkOptions) *EntryBuilder {
+	b.check(b.entry.AddLinkWithOptions(href, rel, options))
+	return b
+}
+
+func (b *EntryBuilder) Published(published time.Time) *EntryBuilder {
+	b.check(b.entry.SetPublished(published))
+	return b
+}
+
+func (b *EntryBuilder) RepliesLink(href string, mediaType string, count int, updated time.Time) *EntryBuilder {
+	b.check(b.entry.AddRepliesLink(href, mediaType, count, updated))
+	return b
+}
+
+// Source attaches the metadata of the feed the entry was taken from, such
+// as one created with CreateSource.
+func (b *EntryBuilder) Source(source *AtomSource) *EntryBuilder {
+	b.check(b.entry.SetSource(source))
+	return b
+}
+
+func (b *EntryBuilder) Summary(text string, textType string) *EntryBuilder {
+	b.check(b.entry.SetSummary(text, textType))
+	return b
+}
+
+func (b *EntryBuilder) Total(total int) *EntryBuilder {
+	b.check(b.entry.SetTotal(total))
+	return b
+}
+
+// Build validates the entry on its own. Requirements which depend on the
+// feed, such as authors, are validated by FeedBuilder.Build.
+func (b *EntryBuilder) Build() (*AtomEntry, error) {
+	if err := b.result(b.entry.Report()); err != nil {
+		return nil, err
+	}
+	return b.entry, nil
+}
+
+// BuildUnvalidated only reports errors of the chained calls, leaving the
+// entry to be validated with the feed it is added to.
+func (b *EntryBuilder) BuildUnvalidated() (*AtomEntry, error) {
+	if err := b.result(ValidationReport{}); err != nil {
+		return nil, err
+	}
+	return b.entry, nil
+}
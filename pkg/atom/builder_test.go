@@ -0,0 +1,146 @@
+package atom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeedBuilder_Defaults(t *testing.T) {
+	date := time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <author>
+    <name>My Website</name>
+  </author>
+  %s
+  <id>https://example.com/feed.xml</id>
+  <link href="https://example.com/feed.xml" rel="self"></link>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+  <entry>
+    <id>https://example.com/entry/1</id>
+    <link href="https://example.com/entry/1" rel="alternate"></link>
+    <title>Entry 1</title>
+    <updated>2022-07-04T12:34:00Z</updated>
+  </entry>
+</feed>`, getGenerator())
+
+	entry, err := NewEntryBuilder("https://example.com/entry/1", "Entry 1", date).
+		Link("https://example.com/entry/1", RelAlternate).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed, err := NewFeedBuilder("https://example.com/feed.xml", "My Website", date).
+		Entry(entry).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, feed.String(), ref)
+	assertEqual(t, feed.Generator.Text, "Generated via "+NAME)
+}
+
+func TestFeedBuilder_KeepsAuthors(t *testing.T) {
+	date := time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)
+
+	feed, err := NewFeedBuilder("urn:uuid:60a76c80-d399-11d9-b93c-0003939e0af6", "My Website", date).
+		Author("John Doe", "", "").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, fmt.Sprint(len(feed.Authors)), "1")
+	assertEqual(t, fmt.Sprint(len(feed.Links)), "0")
+}
+
+func TestFeedBuilder_CollectsErrors(t *testing.T) {
+	date := time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)
+
+	_, err := NewFeedBuilder("example.com", "My Website", date).
+		Language("English (US)").
+		Subtitle("<p>unclosed", "xhtml").
+		Build()
+	if err == nil {
+		t.Fatal("Expected invalid feed fields to throw error")
+	}
+
+	buildErr, ok := err.(BuildError)
+	if !ok {
+		t.Fatalf("Expected BuildError, got %T", err)
+	}
+	assertEqual(t, fmt.Sprint(len(buildErr.Errors)), "2")
+}
+
+func TestFeedBuilder_Validates(t *testing.T) {
+	date := time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)
+
+	_, err := NewFeedBuilder("", "My Website", date).Build()
+	if err == nil {
+		t.Error("Expected feed without an id to throw error")
+	}
+}
+
+func TestEntryBuilder_Validates(t *testing.T) {
+	date := time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)
+
+	_, err := NewEntryBuilder("example.com/entry/1", "Entry 1", date).Build()
+	if err == nil || !strings.Contains(err.Error(), "alternate") {
+		t.Errorf("Expected entry without content or alternate link to throw error, got %v", err)
+	}
+
+	entry, err := NewEntryBuilder("example.com/entry/1", "Entry 1", date).
+		Content("Hello world", "text").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, entry.Content.Text, "Hello world")
+}
+
+func TestFeedBuilder_BuildUnvalidated(t *testing.T) {
+	date := time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)
+	builder := NewFeedBuilder("example.com", "My Website", date).
+		Subtitle("", "text")
+
+	feed, err := builder.BuildUnvalidated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Report().Valid() {
+		t.Error("Expected feed with an empty subtitle to be left invalid")
+	}
+	if len(feed.Generator.Text) == 0 {
+		t.Error("Expected defaults to be filled in")
+	}
+	if _, err := builder.Build(); err == nil {
+		t.Error("Expected Build to reject the feed")
+	}
+
+	_, err = NewFeedBuilder("example.com", "My Website", date).
+		Language("English (US)").
+		BuildUnvalidated()
+	if err == nil {
+		t.Error("Expected errors of chained calls to be returned")
+	}
+}
+
+func TestFeedBuilder_Repair(t *testing.T) {
+	date := time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)
+	builder := NewFeedBuilder("example.com", "My Website", date).
+		Author("", "", "owner@example.com").
+		Subtitle("", "text")
+
+	if repairs := builder.Repair(); len(repairs) == 0 {
+		t.Error("Expected repairs to be listed")
+	}
+	if _, err := builder.Build(); err != nil {
+		t.Errorf("Expected repaired feed to build, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package atom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/xml"
+)
+
+// FeedDiff describes how a feed changed between two snapshots.
+type FeedDiff struct {
+	Added    []AtomEntry // entries only in the new feed
+	Removed  []AtomEntry // entries only in the old feed
+	Modified []AtomEntry // new versions of entries in both feeds
+	Metadata []string    // names of changed feed elements and attributes
+}
+
+// HasChanges reports whether the snapshots differ.
+func (diff FeedDiff) HasChanges() bool {
+	return len(diff.Added) > 0 || len(diff.Removed) > 0 || len(diff.Modified) > 0 || len(diff.Metadata) > 0
+}
+
+// Diff compares two snapshots of the same feed. Entries are matched by
+// atom:id and are modified when atom:updated or any of their content
+// changed. Added and modified entries are in the order of the new feed,
+// while removed entries are in the order of the old feed.
+func Diff(oldFeed *AtomFeed, newFeed *AtomFeed) FeedDiff {
+	var diff FeedDiff
+
+	oldEntries := make(map[AtomID]AtomEntry)
+	for _, entry := range oldFeed.Entries {
+		oldEntries[entry.Id] = entry
+	}
+	newIds := make(map[AtomID]bool)
+
+	for _, entry := range newFeed.Entries {
+		newIds[entry.Id] = true
+		oldEntry, ok := oldEntries[entry.Id]
+		if !ok {
+			diff.Added = append(diff.Added, entry)
+		} else if isModified(oldEntry, entry) {
+			diff.Modified = append(diff.Modified, entry)
+		}
+	}
+
+	for _, entry := range oldFeed.Entries {
+		if !newIds[entry.Id] {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+
+	diff.Metadata = diffMetadata(oldFeed, newFeed)
+
+	return diff
+}
+
+func isModified(oldEntry AtomEntry, newEntry AtomEntry) bool {
+	if !oldEntry.Updated.Equal(newEntry.Updated) {
+		return true
+	}
+	// entries are not required to change atom:updated for minor edits
+	return hashElement(oldEntry) != hashElement(newEntry)
+}
+
+// hashElement hashes the xml encoding of v, so values which are written
+// the same way are considered equal.
+func hashElement(v interface{}) [sha256.Size]byte {
+	var buf bytes.Buffer
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		buf.WriteString(err.Error())
+	}
+	return sha256.Sum256(buf.Bytes())
+}
+
+func diffMetadata(oldFeed *AtomFeed, newFeed *AtomFeed) []string {
+	fields := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"xml:lang", oldFeed.Lang, newFeed.Lang},
+		{"xml:base", oldFeed.Base, newFeed.Base},
+		{"author", oldFeed.Authors, newFeed.Authors},
+		{"category", oldFeed.Categories, newFeed.Categories},
+		{"contributor", oldFeed.Contributors, newFeed.Contributors},
+		{"generator", oldFeed.Generator, newFeed.Generator},
+		{"icon", oldFeed.Icon, newFeed.Icon},
+		{"id", oldFeed.Id, newFeed.Id},
+		{"link", oldFeed.Links, newFeed.Links},
+		{"logo", oldFeed.Logo, newFeed.Logo},
+		{"rights", oldFeed.Rights, newFeed.Rights},
+		{"subtitle", oldFeed.Subtitle, newFeed.Subtitle},
+		{"title", oldFeed.Title, newFeed.Title},
+		{"updated", oldFeed.Updated, newFeed.Updated},
+		{"extensions", oldFeed.Extensions, newFeed.Extensions},
+	}
+
+	var changed []string
+	for _, field := range fields {
+		if hashElement(field.old) != hashElement(field.new) {
+			changed = append(changed, field.name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,79 @@
+package atom
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiff_Entries(t *testing.T) {
+	oldFeed := makeTestFeed(t)
+	oldFeed.AddEntry(makeDatedEntry(t, "a", "2022-01-01"))
+	oldFeed.AddEntry(makeDatedEntry(t, "b", "2022-01-01"))
+	oldFeed.AddEntry(makeDatedEntry(t, "c", "2022-01-01"))
+
+	newFeed := makeTestFeed(t)
+	newFeed.AddEntry(makeDatedEntry(t, "d", "2022-03-01"))
+	newFeed.AddEntry(makeDatedEntry(t, "a", "2022-01-01"))
+	newFeed.AddEntry(makeDatedEntry(t, "b", "2022-02-01"))
+
+	diff := Diff(oldFeed, newFeed)
+
+	assertEqual(t, entryIds(&AtomFeed{Entries: diff.Added}), "d")
+	assertEqual(t, entryIds(&AtomFeed{Entries: diff.Removed}), "c")
+	assertEqual(t, entryIds(&AtomFeed{Entries: diff.Modified}), "b")
+	assertEqual(t, strings.Join(diff.Metadata, ","), "")
+	if !diff.HasChanges() {
+		t.Error("Expected diff to have changes")
+	}
+}
+
+func TestDiff_ContentHash(t *testing.T) {
+	oldFeed := makeTestFeed(t)
+	oldEntry := makeTestEntry(t)
+	oldEntry.SetContent("Hello world", "text")
+	oldFeed.AddEntry(oldEntry)
+
+	newFeed := makeTestFeed(t)
+	newEntry := makeTestEntry(t)
+	newEntry.SetContent("Hello world!", "text")
+	newFeed.AddEntry(newEntry)
+
+	diff := Diff(oldFeed, newFeed)
+
+	assertEqual(t, entryIds(&AtomFeed{Entries: diff.Modified}), "example.com/entry/1")
+}
+
+func TestDiff_Metadata(t *testing.T) {
+	oldFeed := makeTestFeed(t)
+	newFeed := makeTestFeed(t)
+	newFeed.SetTitle("My New Website", "text")
+	newFeed.SetUpdated(time.Now())
+	newFeed.AddLink("example.com", RelSelf)
+
+	diff := Diff(oldFeed, newFeed)
+
+	assertEqual(t, strings.Join(diff.Metadata, ","), "link,title,updated")
+}
+
+func TestDiff_ParsedSnapshot(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddLink("example.com", RelSelf)
+	feed.AddCategory("cats", "", "")
+	entry := makeTestEntry(t)
+	entry.AddLink("example.com/entry/1", RelAlternate)
+	entry.SetContent("<p>Hello</p>", "xhtml")
+	entry.AddExtension(ITunesEpisode(1))
+	feed.AddEntry(entry)
+
+	snapshot, err := Parse(strings.NewReader(feed.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := Diff(snapshot, feed)
+
+	if diff.HasChanges() {
+		t.Errorf("Expected parsed snapshot to equal generated feed, got %+v", diff)
+	}
+}
@@ -0,0 +1,72 @@
+package atom
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Encode writes the feed as an XML document to w as it is marshaled,
+// returning the first marshaling or write error.
+func (feed AtomFeed) Encode(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return encode(w, feed)
+}
+
+func (feed AtomFeed) WriteTo(w io.Writer) (int64, error) {
+	counter := countingWriter{w: w}
+	err := feed.Encode(&counter)
+	return counter.n, err
+}
+
+// Encode writes the entry as an XML element to w. Prefixes of extension
+// elements are declared on the entry since there is no root feed element.
+func (entry AtomEntry) Encode(w io.Writer) error {
+	uris := make(map[string]bool)
+	entry.collectNamespaces(uris)
+	if len(uris) == 0 {
+		return encode(w, entry)
+	}
+
+	start := xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: declareNamespaces(uris),
+	}
+	return encodeElement(w, entry, start)
+}
+
+func (entry AtomEntry) WriteTo(w io.Writer) (int64, error) {
+	counter := countingWriter{w: w}
+	err := entry.Encode(&counter)
+	return counter.n, err
+}
+
+func encode(w io.Writer, v interface{}) error {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func encodeElement(w io.Writer, v interface{}, start xml.StartElement) error {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.EncodeElement(v, start); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (counter *countingWriter) Write(p []byte) (int, error) {
+	n, err := counter.w.Write(p)
+	counter.n += int64(n)
+	return n, err
+}
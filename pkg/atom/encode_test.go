@@ -0,0 +1,74 @@
+package atom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.remaining {
+		n := w.remaining
+		w.remaining = 0
+		return n, errors.New("write failed")
+	}
+	w.remaining -= len(p)
+	return len(p), nil
+}
+
+func TestAtomFeed_Encode(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddEntry(makeTestEntry(t))
+
+	var out strings.Builder
+	err := feed.Encode(&out)
+	if err != nil {
+		t.Error(err)
+	}
+
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  %s
+  <id>example.com</id>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+  <entry>
+    <id>example.com/entry/1</id>
+    <title>Entry 1</title>
+    <updated>2022-07-04T12:34:00Z</updated>
+  </entry>
+</feed>`, getGenerator())
+
+	assertEqual(t, out.String(), ref)
+}
+
+func TestAtomFeed_EncodeWriteError(t *testing.T) {
+	feed := makeTestFeed(t)
+	for i := 0; i < 100; i++ {
+		feed.AddEntry(makeTestEntry(t))
+	}
+
+	err := feed.Encode(&failingWriter{remaining: 1024})
+	if err == nil {
+		t.Error("Expected encoding to a failing writer to throw error")
+	}
+}
+
+func TestAtomFeed_WriteTo(t *testing.T) {
+	feed := makeTestFeed(t)
+
+	var out strings.Builder
+	n, err := feed.WriteTo(&out)
+	if err != nil {
+		t.Error(err)
+	}
+	if n != int64(out.Len()) {
+		t.Errorf("Expected %d bytes written, got %d", out.Len(), n)
+	}
+}
@@ -0,0 +1,341 @@
+// see RFC 4287 section 6
+
+package atom
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const AtomNamespace = "http://www.w3.org/2005/Atom"
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// s6.2
+
+// namespace of extension elements, written with its prefix
+type Namespace struct {
+	Prefix string
+	URI    string
+}
+
+var (
+	ITunesNamespace    = Namespace{"itunes", "http://www.itunes.com/dtds/podcast-1.0.dtd"}
+	MediaNamespace     = Namespace{"media", "http://search.yahoo.com/mrss/"}
+	HistoryNamespace   = Namespace{"fh", "http://purl.org/syndication/history/1.0"}
+	ThreadingNamespace = Namespace{"thr", "http://purl.org/syndication/thread/1.0"}
+)
+
+var (
+	namespacesMutex sync.RWMutex
+	namespaces      = map[string]Namespace{
+		ITunesNamespace.URI:    ITunesNamespace,
+		MediaNamespace.URI:     MediaNamespace,
+		HistoryNamespace.URI:   HistoryNamespace,
+		ThreadingNamespace.URI: ThreadingNamespace,
+	}
+)
+
+// RegisterNamespace allows extension elements of a namespace to be added to
+// feeds and entries. Each prefix can only refer to one namespace.
+func RegisterNamespace(prefix string, uri string) (Namespace, error) {
+	if len(prefix) == 0 || len(uri) == 0 {
+		return Namespace{}, errors.New("namespaces must have a prefix and uri")
+	}
+	if prefix == "xml" || prefix == "xmlns" || uri == AtomNamespace || uri == xmlNamespace {
+		return Namespace{}, errors.New("namespace " + uri + " is reserved")
+	}
+
+	namespacesMutex.Lock()
+	defer namespacesMutex.Unlock()
+
+	for _, ns := range namespaces {
+		if ns.Prefix == prefix && ns.URI != uri {
+			return Namespace{}, errors.New("namespace prefix '" + prefix + "' is already registered for " + ns.URI)
+		}
+	}
+	if ns, ok := namespaces[uri]; ok {
+		return ns, nil
+	}
+
+	ns := Namespace{prefix, uri}
+	namespaces[uri] = ns
+	return ns, nil
+}
+
+func lookupNamespace(uri string) (Namespace, bool) {
+	namespacesMutex.RLock()
+	defer namespacesMutex.RUnlock()
+	ns, ok := namespaces[uri]
+	return ns, ok
+}
+
+// s6.4
+
+// An element from a foreign namespace. Simple extension elements only
+// contain text, while structured extension elements have attributes or
+// child elements.
+type AtomExtension struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr      `xml:",any,attr"`
+	Text     string          `xml:",chardata"`
+	Children []AtomExtension `xml:",any"`
+}
+
+func CreateExtension(ns Namespace, name string, text string) AtomExtension {
+	return AtomExtension{
+		XMLName: xml.Name{Space: ns.URI, Local: name},
+		Text:    text,
+	}
+}
+
+// SetAttr sets an attribute without a namespace.
+func (ext *AtomExtension) SetAttr(name string, value string) {
+	for i, attr := range ext.Attrs {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			ext.Attrs[i].Value = value
+			return
+		}
+	}
+	ext.Attrs = append(ext.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+func (ext *AtomExtension) AddChild(child AtomExtension) {
+	ext.Children = append(ext.Children, child)
+}
+
+func (ext AtomExtension) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{
+		Name: prefixName(ext.XMLName),
+		Attr: prefixAttrs(ext.Attrs),
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if len(ext.Text) > 0 {
+		if err := e.EncodeToken(xml.CharData(ext.Text)); err != nil {
+			return err
+		}
+	}
+	for _, child := range ext.Children {
+		if err := e.Encode(child); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (ext *AtomExtension) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plainExtension AtomExtension
+	var plain plainExtension
+	if err := d.DecodeElement(&plain, &start); err != nil {
+		return err
+	}
+
+	plain.Attrs = withoutNamespaceDecls(plain.Attrs)
+
+	// whitespace between child elements is indentation
+	if len(strings.TrimSpace(plain.Text)) == 0 {
+		plain.Text = ""
+	}
+
+	*ext = AtomExtension(plain)
+	return nil
+}
+
+// prefixName writes names of registered namespaces with their prefix.
+// Names of other namespaces are left to the encoder to declare.
+func prefixName(name xml.Name) xml.Name {
+	if ns, ok := lookupNamespace(name.Space); ok {
+		return xml.Name{Local: ns.Prefix + ":" + name.Local}
+	}
+	return name
+}
+
+func prefixAttrs(attrs []xml.Attr) []xml.Attr {
+	var prefixed []xml.Attr
+	for _, attr := range attrs {
+		attr.Name = prefixName(attr.Name)
+		prefixed = append(prefixed, attr)
+	}
+	return prefixed
+}
+
+// namespace declarations are written from the namespace registry
+func withoutNamespaceDecls(attrs []xml.Attr) []xml.Attr {
+	var filtered []xml.Attr
+	for _, attr := range attrs {
+		if attr.Name.Space != "xmlns" && !(attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			filtered = append(filtered, attr)
+		}
+	}
+	return filtered
+}
+
+func collectAttrNamespaces(attrs []xml.Attr, uris map[string]bool) {
+	for _, attr := range attrs {
+		uris[attr.Name.Space] = true
+	}
+}
+
+func (ext AtomExtension) collectNamespaces(uris map[string]bool) {
+	uris[ext.XMLName.Space] = true
+	collectAttrNamespaces(ext.Attrs, uris)
+	for _, child := range ext.Children {
+		child.collectNamespaces(uris)
+	}
+}
+
+// namespaceAttrs declares the prefixes of every registered namespace used
+// by the extension elements and attributes of the feed and its entries.
+func (feed AtomFeed) namespaceAttrs() []xml.Attr {
+	uris := make(map[string]bool)
+	for _, ext := range feed.Extensions {
+		ext.collectNamespaces(uris)
+	}
+	for _, link := range feed.Links {
+		collectAttrNamespaces(link.Attrs, uris)
+	}
+	for _, entry := range feed.Entries {
+		entry.collectNamespaces(uris)
+	}
+	return declareNamespaces(uris)
+}
+
+func (entry AtomEntry) collectNamespaces(uris map[string]bool) {
+	for _, ext := range entry.Extensions {
+		ext.collectNamespaces(uris)
+	}
+	for _, link := range entry.Links {
+		collectAttrNamespaces(link.Attrs, uris)
+	}
+}
+
+func declareNamespaces(uris map[string]bool) []xml.Attr {
+	var attrs []xml.Attr
+	for uri := range uris {
+		if ns, ok := lookupNamespace(uri); ok {
+			attrs = append(attrs, xml.Attr{
+				Name:  xml.Name{Local: "xmlns:" + ns.Prefix},
+				Value: ns.URI,
+			})
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+	return attrs
+}
+
+func isExtension(name xml.Name) bool {
+	return len(name.Space) > 0 && name.Space != AtomNamespace
+}
+
+// decodeChildren calls decodeChild for each child element until the
+// current element ends. decodeChild must consume the child element.
+func decodeChildren(d *xml.Decoder, decodeChild func(xml.StartElement) error) error {
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if err := decodeChild(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (feed *AtomFeed) AddExtension(ext AtomExtension) error {
+	if err := checkExtension(ext); err != nil {
+		return err
+	}
+	feed.Extensions = append(feed.Extensions, ext)
+	return nil
+}
+
+func (entry *AtomEntry) AddExtension(ext AtomExtension) error {
+	if err := checkExtension(ext); err != nil {
+		return err
+	}
+	entry.Extensions = append(entry.Extensions, ext)
+	return nil
+}
+
+func checkExtension(ext AtomExtension) error {
+	if _, ok := lookupNamespace(ext.XMLName.Space); !ok {
+		return FeedError(fmt.Sprintf("extension element <%s> must belong to a registered namespace", ext.XMLName.Local), "6.2")
+	}
+	return nil
+}
+
+// itunes
+
+func ITunesDuration(duration time.Duration) AtomExtension {
+	seconds := int(duration.Seconds())
+	text := fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	return CreateExtension(ITunesNamespace, "duration", text)
+}
+
+func ITunesSeason(season int) AtomExtension {
+	return CreateExtension(ITunesNamespace, "season", strconv.Itoa(season))
+}
+
+func ITunesEpisode(episode int) AtomExtension {
+	return CreateExtension(ITunesNamespace, "episode", strconv.Itoa(episode))
+}
+
+// one of "full", "trailer" or "bonus"
+func ITunesEpisodeType(episodeType string) AtomExtension {
+	return CreateExtension(ITunesNamespace, "episodeType", episodeType)
+}
+
+func ITunesExplicit(explicit bool) AtomExtension {
+	return CreateExtension(ITunesNamespace, "explicit", strconv.FormatBool(explicit))
+}
+
+func ITunesImage(href string) AtomExtension {
+	ext := CreateExtension(ITunesNamespace, "image", "")
+	ext.SetAttr("href", href)
+	return ext
+}
+
+// media rss
+
+// width and height are omitted when 0
+func MediaThumbnail(url string, width uint, height uint) AtomExtension {
+	ext := CreateExtension(MediaNamespace, "thumbnail", "")
+	ext.SetAttr("url", url)
+	if width > 0 {
+		ext.SetAttr("width", strconv.FormatUint(uint64(width), 10))
+	}
+	if height > 0 {
+		ext.SetAttr("height", strconv.FormatUint(uint64(height), 10))
+	}
+	return ext
+}
+
+// MediaStatistics describes how often an item was viewed or played.
+func MediaStatistics(views int) AtomExtension {
+	stats := CreateExtension(MediaNamespace, "statistics", "")
+	stats.SetAttr("views", strconv.Itoa(views))
+
+	community := CreateExtension(MediaNamespace, "community", "")
+	community.AddChild(stats)
+	return community
+}
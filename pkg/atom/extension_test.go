@@ -0,0 +1,160 @@
+package atom
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAtomFeed_AddExtensions(t *testing.T) {
+	feed := makeTestFeed(t)
+	entry := makeTestEntry(t)
+
+	if err := feed.AddExtension(ITunesExplicit(false)); err != nil {
+		t.Error(err)
+	}
+	if err := entry.AddExtension(ITunesDuration(90 * time.Minute)); err != nil {
+		t.Error(err)
+	}
+	if err := entry.AddExtension(MediaThumbnail("example.com/1.png", 64, 0)); err != nil {
+		t.Error(err)
+	}
+	if err := entry.AddExtension(MediaStatistics(42)); err != nil {
+		t.Error(err)
+	}
+	feed.AddEntry(entry)
+
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:media="http://search.yahoo.com/mrss/">
+  %s
+  <id>example.com</id>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+  <itunes:explicit>false</itunes:explicit>
+  <entry>
+    <id>example.com/entry/1</id>
+    <title>Entry 1</title>
+    <updated>2022-07-04T12:34:00Z</updated>
+    <itunes:duration>01:30:00</itunes:duration>
+    <media:thumbnail url="example.com/1.png" width="64"></media:thumbnail>
+    <media:community>
+      <media:statistics views="42"></media:statistics>
+    </media:community>
+  </entry>
+</feed>`, getGenerator())
+
+	assertEqual(t, feed.String(), ref)
+}
+
+func TestAtomEntry_EncodeExtensions(t *testing.T) {
+	entry := makeTestEntry(t)
+	entry.AddExtension(ITunesEpisode(3))
+	ref :=
+		`<entry xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+  <id>example.com/entry/1</id>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+  <itunes:episode>3</itunes:episode>
+</entry>`
+
+	assertEqual(t, entry.String(), ref)
+}
+
+func TestAtomEntry_AddUnregisteredExtension(t *testing.T) {
+	entry := makeTestEntry(t)
+	ext := CreateExtension(Namespace{"unknown", "urn:example:unknown"}, "thing", "")
+	err := entry.AddExtension(ext)
+	if err == nil {
+		t.Error("Expected extension of an unregistered namespace to throw error")
+	}
+}
+
+func TestRegisterNamespace(t *testing.T) {
+	ns, err := RegisterNamespace("ex", "urn:example:register")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := RegisterNamespace("ex", "urn:example:register")
+	if err != nil || again != ns {
+		t.Error("Expected registering the same namespace twice to succeed")
+	}
+
+	if _, err := RegisterNamespace("ex", "urn:example:other"); err == nil {
+		t.Error("Expected registering a prefix for two namespaces to throw error")
+	}
+	if _, err := RegisterNamespace("atom", AtomNamespace); err == nil {
+		t.Error("Expected registering the atom namespace to throw error")
+	}
+	if _, err := RegisterNamespace("xml", "urn:example:xml"); err == nil {
+		t.Error("Expected registering the xml prefix to throw error")
+	}
+
+	entry := makeTestEntry(t)
+	if err := entry.AddExtension(CreateExtension(ns, "thing", "value")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParse_Extensions(t *testing.T) {
+	doc := `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:x="urn:example:unregistered">
+  <title>Podcast</title>
+  <itunes:author>Not an atom:author</itunes:author>
+  <entry>
+    <title>Episode</title>
+    <summary>Atom summary</summary>
+    <itunes:summary>iTunes summary</itunes:summary>
+    <itunes:image href="example.com/1.png"/>
+    <x:rating scheme="stars">5</x:rating>
+  </entry>
+</feed>`
+
+	feed, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(feed.Authors) != 0 {
+		t.Error("Expected itunes:author not to be parsed as atom:author")
+	}
+	if len(feed.Extensions) != 1 {
+		t.Fatalf("Expected 1 feed extension, got %d", len(feed.Extensions))
+	}
+	assertEqual(t, feed.Extensions[0].Text, "Not an atom:author")
+
+	entry := feed.Entries[0]
+	assertEqual(t, entry.Summary.Text, "Atom summary")
+	if len(entry.Extensions) != 3 {
+		t.Fatalf("Expected 3 entry extensions, got %d", len(entry.Extensions))
+	}
+	assertEqual(t, entry.Extensions[0].Text, "iTunes summary")
+	assertEqual(t, entry.Extensions[1].Attrs[0].Value, "example.com/1.png")
+	assertEqual(t, entry.Extensions[2].XMLName.Space, "urn:example:unregistered")
+
+	out, err := xml.Marshal(entry.Extensions[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, string(out), `<rating xmlns="urn:example:unregistered" scheme="stars">5</rating>`)
+}
+
+func TestParse_ExtensionsRoundTrip(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddExtension(ITunesImage("example.com/logo.png"))
+	entry := makeTestEntry(t)
+	entry.AddExtension(ITunesSeason(2))
+	entry.AddExtension(MediaStatistics(7))
+	feed.AddEntry(entry)
+
+	ref := feed.String()
+
+	parsed, err := Parse(strings.NewReader(ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, parsed.String(), ref)
+}
@@ -2,9 +2,120 @@ package atom
 
 import (
 	"errors"
+	"net/url"
+	"regexp"
 	"time"
 )
 
+// s2
+
+// language tags as defined in RFC 3066
+var languageTagMatcher = regexp.MustCompile(`^[A-Za-z]{1,8}(-[A-Za-z0-9]{1,8})*$`)
+
+func checkLanguage(lang string) error {
+	if len(lang) > 0 && !languageTagMatcher.MatchString(lang) {
+		return FeedError("xml:lang must be a language tag as defined in RFC 3066", "2")
+	}
+	return nil
+}
+
+func checkBase(base string) error {
+	if _, err := url.Parse(base); err != nil {
+		return FeedError("xml:base must be an IRI reference: "+err.Error(), "2")
+	}
+	return nil
+}
+
+func (feed *AtomFeed) SetLanguage(lang string) error {
+	if err := checkLanguage(lang); err != nil {
+		return err
+	}
+	feed.Lang = AtomLanguageTag(lang)
+	return nil
+}
+
+func (entry *AtomEntry) SetLanguage(lang string) error {
+	if err := checkLanguage(lang); err != nil {
+		return err
+	}
+	entry.Lang = AtomLanguageTag(lang)
+	return nil
+}
+
+// SetBase sets the IRI that relative references within the feed resolve
+// against.
+func (feed *AtomFeed) SetBase(base string) error {
+	if err := checkBase(base); err != nil {
+		return err
+	}
+	feed.Base = AtomURI(base)
+	return nil
+}
+
+func (entry *AtomEntry) SetBase(base string) error {
+	if err := checkBase(base); err != nil {
+		return err
+	}
+	entry.Base = AtomURI(base)
+	return nil
+}
+
+// SetLanguage sets the language of the text, overriding the language of the
+// enclosing feed or entry. Titles, subtitles, summaries and rights are
+// converted to set theirs, as in (*AtomTextConstruct)(feed.Subtitle).
+func (text *AtomTextConstruct) SetLanguage(lang string) error {
+	if err := checkLanguage(lang); err != nil {
+		return err
+	}
+	text.Lang = AtomLanguageTag(lang)
+	return nil
+}
+
+func (text *AtomTextConstruct) SetBase(base string) error {
+	if err := checkBase(base); err != nil {
+		return err
+	}
+	text.Base = AtomURI(base)
+	return nil
+}
+
+func (content *AtomContent) SetLanguage(lang string) error {
+	if err := checkLanguage(lang); err != nil {
+		return err
+	}
+	content.Lang = AtomLanguageTag(lang)
+	return nil
+}
+
+func (content *AtomContent) SetBase(base string) error {
+	if err := checkBase(base); err != nil {
+		return err
+	}
+	content.Base = AtomURI(base)
+	return nil
+}
+
+// s3.1.1
+
+func checkTextType(text string, textType string) error {
+	if textType == "xhtml" {
+		if err := checkXHTML(text); err != nil {
+			return FeedError("xhtml text constructs must be well-formed: "+err.Error(), "3.1.1.3")
+		}
+	}
+	return nil
+}
+
+// s3.2
+
+func createPerson(name string, uri string, email string) AtomPersonConstruct {
+	return AtomPersonConstruct{
+		Name:  AtomName(name),
+		Uri:   AtomURI(uri),
+		Email: AtomEmail(email),
+	}
+}
+
 // s3.3
 
 func (feed *AtomFeed) SetUpdated(date time.Time) error {
@@ -29,7 +140,8 @@ func CreateFeed(id string, title string, updated time.Time) (*AtomFeed, error) {
 	}
 
 	feed := AtomFeed{
-		Id: AtomID(id),
+		Generator: defaultGenerator(),
+		Id:        AtomID(id),
 		Title: AtomTitle{
 			Text: title,
 		},
@@ -71,6 +183,11 @@ func (feed *AtomFeed) AddEntry(entry *AtomEntry) error {
 // s4.1.3
 
 func (entry *AtomEntry) SetContent(content string, contentType string) error {
+	if contentType == "xhtml" {
+		if err := checkXHTML(content); err != nil {
+			return FeedError("xhtml content must be well-formed: "+err.Error(), "4.1.3.3")
+		}
+	}
 	entry.Content = &AtomContent{
 		Text: content,
 		Type: contentType,
@@ -81,13 +198,12 @@ func (entry *AtomEntry) SetContent(content string, contentType string) error {
 // s4.2.1
 
 func (feed *AtomFeed) AddAuthor(name string, uri string, email string) error {
-	author := AtomAuthor{
-		Name:  AtomName(name),
-		Uri:   AtomURI(uri),
-		Email: AtomEmail(email),
-	}
-	feed.Authors = append(feed.Authors, author)
+	feed.Authors = append(feed.Authors, AtomAuthor(createPerson(name, uri, email)))
+	return nil
+}
 
+func (entry *AtomEntry) AddAuthor(name string, uri string, email string) error {
+	entry.Authors = append(entry.Authors, AtomAuthor(createPerson(name, uri, email)))
 	return nil
 }
 
@@ -113,25 +229,61 @@ func (entry *AtomEntry) AddCategory(term string, scheme string, label string) er
 	return nil
 }
 
+// s4.2.3
+
+func (feed *AtomFeed) AddContributor(name string, uri string, email string) error {
+	feed.Contributors = append(feed.Contributors, AtomContributor(createPerson(name, uri, email)))
+	return nil
+}
+
+func (entry *AtomEntry) AddContributor(name string, uri string, email string) error {
+	entry.Contributors = append(entry.Contributors, AtomContributor(createPerson(name, uri, email)))
+	return nil
+}
+
+// s4.2.5
+
+func (feed *AtomFeed) SetIcon(uri string) error {
+	feed.Icon = AtomIcon(uri)
+	return nil
+}
+
 // s4.2.7
 
-func (feed *AtomFeed) AddLink(href string, rel AtomRelType) error {
-	link := AtomLink{
-		Href: AtomURI(href),
-		Rel:  rel,
+// optional atom:link attributes
+type LinkOptions struct {
+	Type     string // advisory media type
+	HrefLang string // language of the linked resource
+	Title    string
+	Length   uint // advisory length in bytes
+}
+
+func createLink(href string, rel AtomRelType, options LinkOptions) AtomLink {
+	return AtomLink{
+		Href:     AtomURI(href),
+		Rel:      rel,
+		Type:     AtomMediaType(options.Type),
+		HrefLang: AtomLanguageTag(options.HrefLang),
+		Title:    options.Title,
+		Length:   options.Length,
 	}
-	feed.Links = append(feed.Links, link)
+}
+
+func (feed *AtomFeed) AddLink(href string, rel AtomRelType) error {
+	return feed.AddLinkWithOptions(href, rel, LinkOptions{})
+}
 
+func (feed *AtomFeed) AddLinkWithOptions(href string, rel AtomRelType, options LinkOptions) error {
+	feed.Links = append(feed.Links, createLink(href, rel, options))
 	return nil
 }
 
 func (entry *AtomEntry) AddLink(href string, rel AtomRelType) error {
-	link := AtomLink{
-		Href: AtomURI(href),
-		Rel:  rel,
-	}
-	entry.Links = append(entry.Links, link)
+	return entry.AddLinkWithOptions(href, rel, LinkOptions{})
+}
 
+func (entry *AtomEntry) AddLinkWithOptions(href string, rel AtomRelType, options LinkOptions) error {
+	entry.Links = append(entry.Links, createLink(href, rel, options))
 	return nil
 }
 
@@ -153,6 +305,9 @@ func (entry *AtomEntry) SetPublished(published time.Time) error {
 // s4.2.10
 
 func (feed *AtomFeed) SetCopyright(text string, textType string) error {
+	if err := checkTextType(text, textType); err != nil {
+		return err
+	}
 	rights := AtomRights{
 		Text: text,
 		Type: AtomTextType(textType),
@@ -161,9 +316,68 @@ func (feed *AtomFeed) SetCopyright(text string, textType string) error {
 	return nil
 }
 
+func (entry *AtomEntry) SetCopyright(text string, textType string) error {
+	if err := checkTextType(text, textType); err != nil {
+		return err
+	}
+	rights := AtomRights{
+		Text: text,
+		Type: AtomTextType(textType),
+	}
+	entry.Rights = &rights
+	return nil
+}
+
+// s4.2.11
+
+// CreateSource copies the metadata of a feed so it can be attached to
+// entries taken from that feed.
+func CreateSource(feed *AtomFeed) *AtomSource {
+	generator := feed.Generator
+	title := feed.Title
+	updated := feed.Updated
+
+	source := AtomSource{
+		Authors:      append([]AtomAuthor(nil), feed.Authors...),
+		Categories:   append([]AtomCategory(nil), feed.Categories...),
+		Contributors: append([]AtomContributor(nil), feed.Contributors...),
+		Icon:         feed.Icon,
+		Id:           feed.Id,
+		Links:        append([]AtomLink(nil), feed.Links...),
+		Logo:         feed.Logo,
+	}
+	if len(generator.Text) > 0 {
+		source.Generator = &generator
+	}
+	if feed.Rights != nil {
+		rights := *feed.Rights
+		source.Rights = &rights
+	}
+	if feed.Subtitle != nil {
+		subtitle := *feed.Subtitle
+		source.Subtitle = &subtitle
+	}
+	if len(title.Text) > 0 {
+		source.Title = &title
+	}
+	if !time.Time(updated).IsZero() {
+		source.Updated = &updated
+	}
+
+	return &source
+}
+
+func (entry *AtomEntry) SetSource(source *AtomSource) error {
+	entry.Source = source
+	return nil
+}
+
 // s4.2.12
 
 func (feed *AtomFeed) SetSubtitle(text string, textType string) error {
+	if err := checkTextType(text, textType); err != nil {
+		return err
+	}
 	subtitle := AtomSubtitle{
 		Text: text,
 		Type: AtomTextType(textType),
@@ -176,6 +390,9 @@ func (feed *AtomFeed) SetSubtitle(text string, textType string) error {
 // s4.2.13
 
 func (entry *AtomEntry) SetSummary(text string, textType string) error {
+	if err := checkTextType(text, textType); err != nil {
+		return err
+	}
 	summary := AtomSummary{
 		Text: text,
 		Type: AtomTextType(textType),
@@ -191,6 +408,9 @@ func (feed *AtomFeed) SetTitle(text string, textType string) error {
 	if len(text) == 0 {
 		return nil
 	}
+	if err := checkTextType(text, textType); err != nil {
+		return err
+	}
 	title := AtomTitle{
 		Text: text,
 		Type: AtomTextType(textType),
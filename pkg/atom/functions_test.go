@@ -2,10 +2,87 @@ package atom
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
 
+// s2
+
+func TestAtomFeed_SetLanguage(t *testing.T) {
+	feed := makeTestFeed(t)
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en-US" xml:base="https://example.com/blog/">
+  %s
+  <id>example.com</id>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</feed>`, getGenerator())
+
+	if err := feed.SetLanguage("en-US"); err != nil {
+		t.Error(err)
+	}
+	if err := feed.SetBase("https://example.com/blog/"); err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, feed.String(), ref)
+}
+
+func TestAtomFeed_SetInvalidLanguage(t *testing.T) {
+	feed := makeTestFeed(t)
+
+	if err := feed.SetLanguage("English (US)"); err == nil {
+		t.Error("Expected invalid xml:lang to throw error")
+	}
+	assertEqual(t, string(feed.Lang), "")
+}
+
+func TestAtomEntry_SetBase(t *testing.T) {
+	entry := makeTestEntry(t)
+	ref :=
+		`<entry xml:lang="fr" xml:base="gemini://example.com/log/entry.gmi">
+  <id>example.com/entry/1</id>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</entry>`
+
+	entry.SetLanguage("fr")
+	if err := entry.SetBase("gemini://example.com/log/entry.gmi"); err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, entry.String(), ref)
+}
+
+func TestAtomTextConstruct_SetLanguage(t *testing.T) {
+	entry := makeTestEntry(t)
+	ref :=
+		`<entry xml:lang="en">
+  <content type="html" xml:base="https://example.com/entry/">&lt;a href=&#34;1&#34;&gt;Entry 1&lt;/a&gt;</content>
+  <id>example.com/entry/1</id>
+  <summary xml:lang="de">Eintrag 1</summary>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</entry>`
+
+	entry.SetLanguage("en")
+	entry.SetSummary("Eintrag 1", "")
+	if err := (*AtomTextConstruct)(entry.Summary).SetLanguage("de"); err != nil {
+		t.Error(err)
+	}
+	entry.SetContent(`<a href="1">Entry 1</a>`, "html")
+	if err := entry.Content.SetBase("https://example.com/entry/"); err != nil {
+		t.Error(err)
+	}
+	if err := entry.Content.SetLanguage("English (US)"); err == nil {
+		t.Error("Expected invalid xml:lang to throw error")
+	}
+
+	assertEqual(t, entry.String(), ref)
+}
+
 // s4.1.1
 
 func TestAtomFeed_CreateFeed(t *testing.T) {
@@ -88,6 +165,45 @@ func TestAtomEntry_SetContent(t *testing.T) {
 	assertEqual(t, test, ref)
 }
 
+func TestAtomEntry_SetXhtmlContent(t *testing.T) {
+	entry := makeTestEntry(t)
+	ref :=
+		`<entry>
+  <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>This is a paragraph<br /></p></div></content>
+  <id>example.com/entry/1</id>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</entry>`
+
+	err := entry.SetContent("<p>This is a paragraph<br /></p>", "xhtml")
+	if err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, entry.String(), ref)
+}
+
+func TestAtomEntry_SetMalformedXhtmlContent(t *testing.T) {
+	malformed := []string{
+		"<p>Unclosed paragraph",
+		"<p>Closed twice</p></p>",
+		"</div><div>Escaped wrapper",
+		"<p>Named&nbsp;entity</p>",
+		`<audio controls></audio>`,
+	}
+
+	for _, content := range malformed {
+		entry := makeTestEntry(t)
+		err := entry.SetContent(content, "xhtml")
+		if err == nil {
+			t.Errorf("Expected malformed xhtml content %q to throw error", content)
+		}
+		if entry.Content != nil {
+			t.Errorf("Expected malformed xhtml content %q not to be set", content)
+		}
+	}
+}
+
 // s4.2.1
 
 func TestAtomFeed_AddFirstAuthor(t *testing.T) {
@@ -175,6 +291,101 @@ func TestAtomFeed_AddMultipleAuthors(t *testing.T) {
 	assertEqual(t, feed.String(), ref)
 }
 
+func TestAtomEntry_AddAuthor(t *testing.T) {
+	entry := makeTestEntry(t)
+	ref :=
+		`<entry>
+  <author>
+    <name>John Doe</name>
+    <uri>johndoe.com</uri>
+  </author>
+  <id>example.com/entry/1</id>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</entry>`
+
+	err := entry.AddAuthor("John Doe", "johndoe.com", "")
+	if err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, entry.String(), ref)
+}
+
+// s4.2.3
+
+func TestAtomFeed_AddContributor(t *testing.T) {
+	feed := makeTestFeed(t)
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <contributor>
+    <name>Bob Smith</name>
+    <email>bob@motors.com</email>
+  </contributor>
+  %s
+  <id>example.com</id>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</feed>`, getGenerator())
+
+	err := feed.AddContributor("Bob Smith", "", "bob@motors.com")
+	if err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, feed.String(), ref)
+}
+
+func TestAtomEntry_AddContributors(t *testing.T) {
+	entry := makeTestEntry(t)
+	ref :=
+		`<entry>
+  <contributor>
+    <name>John Doe</name>
+  </contributor>
+  <contributor>
+    <name>Bob Smith</name>
+  </contributor>
+  <id>example.com/entry/1</id>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</entry>`
+
+	err1 := entry.AddContributor("John Doe", "", "")
+	if err1 != nil {
+		t.Error(err1)
+	}
+	err2 := entry.AddContributor("Bob Smith", "", "")
+	if err2 != nil {
+		t.Error(err2)
+	}
+
+	assertEqual(t, entry.String(), ref)
+}
+
+// s4.2.5
+
+func TestAtomFeed_SetIcon(t *testing.T) {
+	feed := makeTestFeed(t)
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  %s
+  <icon>example.com/favicon.ico</icon>
+  <id>example.com</id>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</feed>`, getGenerator())
+
+	err := feed.SetIcon("example.com/favicon.ico")
+	if err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, feed.String(), ref)
+}
+
 // s4.2.7
 
 func TestAtomFeed_AddFirstLink(t *testing.T) {
@@ -263,6 +474,50 @@ func TestAtomEntry_AddFirstLink(t *testing.T) {
 	assertEqual(t, entry.String(), ref2)
 }
 
+func TestAtomEntry_AddLinkWithOptions(t *testing.T) {
+	entry := makeTestEntry(t)
+	ref :=
+		`<entry>
+  <id>example.com/entry/1</id>
+  <link href="example.com/entry/1.mp3" rel="enclosure" type="audio/mpeg" hreflang="en" title="Episode 1" length="1337"></link>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</entry>`
+
+	err := entry.AddLinkWithOptions("example.com/entry/1.mp3", RelEnclosure, LinkOptions{
+		Type:     "audio/mpeg",
+		HrefLang: "en",
+		Title:    "Episode 1",
+		Length:   1337,
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, entry.String(), ref)
+}
+
+func TestAtomFeed_AddCustomLinkRelations(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddLink("example.com/page/2", RelNext)
+	feed.AddLink("hub.example.com", RelHub)
+	feed.AddLink("example.com/license", "http://example.com/rel/license")
+
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  %s
+  <id>example.com</id>
+  <link href="example.com/page/2" rel="next"></link>
+  <link href="hub.example.com" rel="hub"></link>
+  <link href="example.com/license" rel="http://example.com/rel/license"></link>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</feed>`, getGenerator())
+
+	assertEqual(t, feed.String(), ref)
+}
+
 // s4.2.9
 
 func TestAtomEntry_SetPublished(t *testing.T) {
@@ -288,6 +543,85 @@ func TestAtomEntry_SetPublished(t *testing.T) {
 	assertEqual(t, entry.String(), ref)
 }
 
+// s4.2.10
+
+func TestAtomEntry_SetCopyright(t *testing.T) {
+	entry := makeTestEntry(t)
+	ref :=
+		`<entry>
+  <id>example.com/entry/1</id>
+  <rights type="text">© 2022 John Doe</rights>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</entry>`
+
+	if err := entry.SetCopyright("<b>unclosed", "xhtml"); err == nil {
+		t.Error("Expected malformed xhtml to throw error")
+	}
+	err := entry.SetCopyright("© 2022 John Doe", "text")
+	if err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, entry.String(), ref)
+}
+
+// s4.2.11
+
+func TestAtomEntry_SetSource(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddAuthor("John Doe", "", "")
+	feed.AddLink("example.com", RelSelf)
+
+	entry := makeTestEntry(t)
+	ref :=
+		fmt.Sprintf(`<entry>
+  <id>example.com/entry/1</id>
+  <source>
+    <author>
+      <name>John Doe</name>
+    </author>
+    %s
+    <id>example.com</id>
+    <link href="example.com" rel="self"></link>
+    <title>My Website</title>
+    <updated>2022-07-04T12:34:00Z</updated>
+  </source>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</entry>`, getGenerator())
+
+	err := entry.SetSource(CreateSource(feed))
+	if err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, entry.String(), ref)
+}
+
+func TestCreateSource_CopiesFeed(t *testing.T) {
+	feed, err := Parse(strings.NewReader(`<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>example.com</id>
+  <rights>Copyright</rights>
+  <subtitle>Subtitle</subtitle>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</feed>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := CreateSource(feed)
+	feed.Rights.Text = "Changed"
+	feed.Subtitle.Text = "Changed"
+
+	if source.Generator != nil {
+		t.Errorf("Expected no generator for a feed without one, got %v", *source.Generator)
+	}
+	assertEqual(t, source.Rights.Text, "Copyright")
+	assertEqual(t, source.Subtitle.Text, "Subtitle")
+}
+
 // s4.2.12
 
 func TestAtomFeed_SetSubtitle(t *testing.T) {
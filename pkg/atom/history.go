@@ -0,0 +1,102 @@
+// see RFC 5005
+
+package atom
+
+import "errors"
+
+var pageRels = []AtomRelType{RelFirst, RelPrevious, RelNext, RelLast}
+
+// s2
+
+// SetComplete marks the feed as a complete feed, which contains every entry
+// of the feed in a single document. Complete feeds cannot be paged.
+func (feed *AtomFeed) SetComplete() error {
+	for _, rel := range pageRels {
+		if hasLink(feed.Links, rel) {
+			return errors.New("complete feeds cannot contain paging links")
+		}
+	}
+	feed.setHistoryMarker("complete")
+	return nil
+}
+
+// s3
+
+// links between the pages of a paged feed, omitted when empty
+type PageLinks struct {
+	First    string
+	Previous string
+	Next     string
+	Last     string
+}
+
+// SetPageLinks replaces the paging links of the feed. Paged feeds may be
+// incomplete, so clients should not assume entries missing from a page
+// were deleted. Complete feeds cannot be paged.
+func (feed *AtomFeed) SetPageLinks(links PageLinks) error {
+	hasPageLinks := len(links.First) > 0 || len(links.Previous) > 0 ||
+		len(links.Next) > 0 || len(links.Last) > 0
+	if hasPageLinks && feed.hasHistoryMarker("complete") {
+		return errors.New("complete feeds cannot contain paging links")
+	}
+
+	feed.replaceLinks(RelFirst, links.First)
+	feed.replaceLinks(RelPrevious, links.Previous)
+	feed.replaceLinks(RelNext, links.Next)
+	feed.replaceLinks(RelLast, links.Last)
+	return nil
+}
+
+// s4
+
+// links between the documents of an archived feed, omitted when empty
+type ArchiveLinks struct {
+	Current     string // subscription document containing the newest entries
+	PrevArchive string // archive document with older entries
+	NextArchive string // archive document with newer entries
+}
+
+// SetArchive marks the feed as an archive document, whose entries do not
+// change.
+func (feed *AtomFeed) SetArchive() error {
+	feed.setHistoryMarker("archive")
+	return nil
+}
+
+// SetArchiveLinks replaces the archive links of the feed.
+func (feed *AtomFeed) SetArchiveLinks(links ArchiveLinks) error {
+	feed.replaceLinks(RelCurrent, links.Current)
+	feed.replaceLinks(RelPrevArchive, links.PrevArchive)
+	feed.replaceLinks(RelNextArchive, links.NextArchive)
+	return nil
+}
+
+func (feed *AtomFeed) hasHistoryMarker(name string) bool {
+	for _, ext := range feed.Extensions {
+		if ext.XMLName.Space == HistoryNamespace.URI && ext.XMLName.Local == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (feed *AtomFeed) setHistoryMarker(name string) {
+	if !feed.hasHistoryMarker(name) {
+		feed.AddExtension(CreateExtension(HistoryNamespace, name, ""))
+	}
+}
+
+func (feed *AtomFeed) replaceLinks(rel AtomRelType, href string) {
+	// a new slice keeps copies sharing the backing array intact
+	var links []AtomLink
+	for _, link := range feed.Links {
+		if link.Rel != rel {
+			links = append(links, link)
+		}
+	}
+	feed.Links = links
+
+	if len(href) > 0 {
+		feed.AddLink(href, rel)
+	}
+}
@@ -0,0 +1,115 @@
+package atom
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// s2
+
+func TestAtomFeed_SetComplete(t *testing.T) {
+	feed := makeTestFeed(t)
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:fh="http://purl.org/syndication/history/1.0">
+  %s
+  <id>example.com</id>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+  <fh:complete></fh:complete>
+</feed>`, getGenerator())
+
+	feed.SetComplete()
+	feed.SetComplete()
+
+	assertEqual(t, feed.String(), ref)
+}
+
+// s3
+
+func TestAtomFeed_SetPageLinks(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddLink("example.com?page=2", RelSelf)
+	feed.AddLink("example.com?page=9", RelNext)
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  %s
+  <id>example.com</id>
+  <link href="example.com?page=2" rel="self"></link>
+  <link href="example.com" rel="first"></link>
+  <link href="example.com?page=1" rel="previous"></link>
+  <link href="example.com?page=3" rel="next"></link>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</feed>`, getGenerator())
+
+	feed.SetPageLinks(PageLinks{
+		First:    "example.com",
+		Previous: "example.com?page=1",
+		Next:     "example.com?page=3",
+	})
+
+	assertEqual(t, feed.String(), ref)
+}
+
+func TestAtomFeed_CompleteWithPageLinks(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.SetComplete()
+	if err := feed.SetPageLinks(PageLinks{Next: "example.com?page=2"}); err == nil {
+		t.Error("Expected paging links on a complete feed to throw error")
+	}
+
+	paged := makeTestFeed(t)
+	paged.SetPageLinks(PageLinks{Next: "example.com?page=2"})
+	if err := paged.SetComplete(); err == nil {
+		t.Error("Expected a paged feed marked as complete to throw error")
+	}
+
+	_, err := NewFeedBuilder("example.com", "My Website", time.Time(feed.Updated)).
+		Complete().
+		PageLinks(PageLinks{Next: "example.com?page=2"}).
+		Build()
+	if err == nil {
+		t.Error("Expected builder to report paging links on a complete feed")
+	}
+}
+
+func TestAtomFeed_SetPageLinksKeepsCopies(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddLink("example.com?page=3", RelNext)
+	feed.AddLink("example.com", RelSelf)
+	source := CreateSource(feed)
+	feed.Links = source.Links
+
+	feed.SetPageLinks(PageLinks{})
+
+	assertEqual(t, string(source.Links[0].Rel), string(RelNext))
+	assertEqual(t, string(source.Links[1].Rel), string(RelSelf))
+}
+
+// s4
+
+func TestAtomFeed_SetArchive(t *testing.T) {
+	feed := makeTestFeed(t)
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:fh="http://purl.org/syndication/history/1.0">
+  %s
+  <id>example.com</id>
+  <link href="example.com" rel="current"></link>
+  <link href="example.com/2021" rel="prev-archive"></link>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+  <fh:archive></fh:archive>
+</feed>`, getGenerator())
+
+	feed.SetArchive()
+	feed.SetArchiveLinks(ArchiveLinks{
+		Current:     "example.com",
+		PrevArchive: "example.com/2021",
+	})
+
+	assertEqual(t, feed.String(), ref)
+}
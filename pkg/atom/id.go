@@ -0,0 +1,93 @@
+// see RFC 4287 section 4.2.6.1
+
+package atom
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tag URIs as defined in RFC 4151
+
+// TagURI creates a tag URI such as "tag:example.com,2022-07-04:/entry/1".
+// The authority is a domain name or email address owned by the minter of
+// the id on the given date, and the specific string identifies the resource
+// within that authority.
+func TagURI(authority string, date time.Time, specific string) (string, error) {
+	if len(authority) == 0 {
+		return "", errors.New("tag authority cannot be empty")
+	}
+	if strings.ContainsAny(authority, ",: \t\r\n/") {
+		return "", errors.New("tag authority '" + authority + "' must be a domain name or email address")
+	}
+	if date.IsZero() {
+		return "", errors.New("tag date cannot be empty")
+	}
+
+	tag := "tag:" + strings.ToLower(authority) + "," + date.UTC().Format("2006-01-02") + ":" + escapeTagSpecific(specific)
+	return tag, nil
+}
+
+// escapeTagSpecific percent-encodes characters not allowed in the specific
+// part of a tag URI.
+func escapeTagSpecific(specific string) string {
+	const allowed = "-._~!$&'()*+,;=:@/?"
+
+	var b strings.Builder
+	for i := 0; i < len(specific); i++ {
+		c := specific[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(allowed, c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// UUIDs as defined in RFC 4122
+
+type UUID [16]byte
+
+// namespaces defined in RFC 4122 appendix C
+var (
+	NamespaceDNS = UUID{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	NamespaceURL = UUID{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+)
+
+// NewUUIDv5 creates a name-based UUID, so the same namespace and name
+// always create the same UUID.
+func NewUUIDv5(namespace UUID, name string) UUID {
+	hash := sha1.New()
+	hash.Write(namespace[:])
+	hash.Write([]byte(name))
+
+	var uuid UUID
+	copy(uuid[:], hash.Sum(nil))
+	uuid[6] = uuid[6]&0x0f | 0x50 // version 5
+	uuid[8] = uuid[8]&0x3f | 0x80 // RFC 4122 variant
+	return uuid
+}
+
+func (uuid UUID) String() string {
+	var b [36]byte
+	hex.Encode(b[0:8], uuid[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], uuid[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], uuid[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], uuid[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], uuid[10:])
+	return string(b[:])
+}
+
+// URN formats the UUID as a urn:uuid: URI as recommended for atom:id.
+func (uuid UUID) URN() string {
+	return "urn:uuid:" + uuid.String()
+}
@@ -0,0 +1,40 @@
+package atom
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTagURI(t *testing.T) {
+	date := time.Date(2022, 7, 4, 23, 0, 0, 0, time.FixedZone("", -2*60*60))
+
+	tag, err := TagURI("Example.com", date, "/entry/1 draft#2")
+	if err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, tag, "tag:example.com,2022-07-05:/entry/1%20draft%232")
+}
+
+func TestTagURI_InvalidAuthority(t *testing.T) {
+	date := time.Date(2022, 7, 4, 0, 0, 0, 0, time.UTC)
+
+	if _, err := TagURI("", date, "entry"); err == nil {
+		t.Error("Expected empty tag authority to throw error")
+	}
+	if _, err := TagURI("https://example.com", date, "entry"); err == nil {
+		t.Error("Expected tag authority with a scheme to throw error")
+	}
+	if _, err := TagURI("example.com", time.Time{}, "entry"); err == nil {
+		t.Error("Expected empty tag date to throw error")
+	}
+}
+
+func TestNewUUIDv5(t *testing.T) {
+	// reference value from RFC 9562 appendix A.4
+	uuid := NewUUIDv5(NamespaceDNS, "www.example.com")
+
+	assertEqual(t, uuid.String(), "2ed6657d-e927-568b-95e1-2665a8aea6a2")
+	assertEqual(t, uuid.URN(), "urn:uuid:2ed6657d-e927-568b-95e1-2665a8aea6a2")
+	assertEqual(t, NewUUIDv5(NamespaceDNS, "www.example.com").URN(), uuid.URN())
+}
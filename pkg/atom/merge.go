@@ -0,0 +1,145 @@
+package atom
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Merge adds the entries of other feeds to the feed. Entries without an
+// atom:source element are given one describing the feed they came from.
+// Duplicate entries are removed and atom:updated is moved forward to the
+// newest merged entry.
+func (feed *AtomFeed) Merge(feeds ...*AtomFeed) error {
+	for _, other := range feeds {
+		if other == nil || other == feed {
+			continue
+		}
+
+		source := CreateSource(other)
+		for _, entry := range other.Entries {
+			if entry.Source == nil {
+				entry.Source = source
+			}
+			feed.Entries = append(feed.Entries, entry)
+		}
+	}
+
+	feed.Dedupe()
+
+	if updated, ok := newestUpdated(feed.Entries); ok && updated.After(time.Time(feed.Updated)) {
+		feed.Updated = AtomDate(updated)
+	}
+	return nil
+}
+
+// Dedupe removes entries sharing an atom:id, keeping the entry with the
+// newest atom:updated in the position of the first occurrence.
+func (feed *AtomFeed) Dedupe() error {
+	indices := make(map[AtomID]int)
+	entries := feed.Entries[:0]
+	for _, entry := range feed.Entries {
+		i, ok := indices[entry.Id]
+		if !ok {
+			indices[entry.Id] = len(entries)
+			entries = append(entries, entry)
+			continue
+		}
+		if entry.Updated.After(entries[i].Updated) {
+			entries[i] = entry
+		}
+	}
+	feed.Entries = entries
+	return nil
+}
+
+// SortByUpdated orders entries from newest to oldest atom:updated. Entries
+// updated at the same time keep their order.
+func (feed *AtomFeed) SortByUpdated() error {
+	sort.SliceStable(feed.Entries, func(i, j int) bool {
+		return feed.Entries[i].Updated.After(feed.Entries[j].Updated)
+	})
+	return nil
+}
+
+// SortByPublished orders entries from newest to oldest atom:published.
+// Entries without atom:published are ordered by atom:updated instead.
+func (feed *AtomFeed) SortByPublished() error {
+	sort.SliceStable(feed.Entries, func(i, j int) bool {
+		return publishedTime(feed.Entries[i]).After(publishedTime(feed.Entries[j]))
+	})
+	return nil
+}
+
+func publishedTime(entry AtomEntry) time.Time {
+	if entry.Published != nil {
+		return time.Time(*entry.Published)
+	}
+	return time.Time(entry.Updated)
+}
+
+// Limit keeps only the first n entries.
+func (feed *AtomFeed) Limit(n int) error {
+	if n < 0 {
+		return errors.New("entry limit cannot be negative")
+	}
+	if len(feed.Entries) > n {
+		feed.Entries = feed.Entries[:n]
+	}
+	return nil
+}
+
+// Filter keeps only the entries for which keep returns true.
+func (feed *AtomFeed) Filter(keep func(entry AtomEntry) bool) error {
+	entries := feed.Entries[:0]
+	for _, entry := range feed.Entries {
+		if keep(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	feed.Entries = entries
+	return nil
+}
+
+// RefreshUpdated sets atom:updated to the newest atom:updated of its
+// entries. Feeds without entries are left unchanged.
+func (feed *AtomFeed) RefreshUpdated() error {
+	if updated, ok := newestUpdated(feed.Entries); ok {
+		feed.Updated = AtomDate(updated)
+	}
+	return nil
+}
+
+// NormalizeDates writes every date of the feed, its entries and their
+// sources in the given location, such as time.UTC. Dates keep their instant
+// and precision.
+func (feed *AtomFeed) NormalizeDates(loc *time.Location) error {
+	if loc == nil {
+		return errors.New("location cannot be nil")
+	}
+
+	feed.Updated = feed.Updated.In(loc)
+	for i := range feed.Entries {
+		entry := &feed.Entries[i]
+		entry.Updated = entry.Updated.In(loc)
+		if entry.Published != nil {
+			published := entry.Published.In(loc)
+			entry.Published = &published
+		}
+		if entry.Source != nil && entry.Source.Updated != nil {
+			updated := entry.Source.Updated.In(loc)
+			entry.Source.Updated = &updated
+		}
+	}
+	return nil
+}
+
+func newestUpdated(entries []AtomEntry) (time.Time, bool) {
+	var newest time.Time
+	for _, entry := range entries {
+		if updated := time.Time(entry.Updated); updated.After(newest) {
+			newest = updated
+		}
+	}
+	return newest, !newest.IsZero()
+}
@@ -0,0 +1,160 @@
+package atom
+
+import (
+	"testing"
+	"time"
+)
+
+func makeDatedEntry(t *testing.T, id string, updated string) *AtomEntry {
+	date, err := time.Parse("2006-01-02", updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := CreateFeedEntry(id, id, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func entryIds(feed *AtomFeed) string {
+	var ids string
+	for i, entry := range feed.Entries {
+		if i > 0 {
+			ids += ","
+		}
+		ids += string(entry.Id)
+	}
+	return ids
+}
+
+func TestAtomFeed_Merge(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddEntry(makeDatedEntry(t, "a", "2022-01-01"))
+
+	other, err := CreateFeed("example.com/other", "Other Website", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.AddAuthor("Jane Doe", "", "")
+	other.AddEntry(makeDatedEntry(t, "b", "2023-01-01"))
+
+	feed.Merge(other)
+
+	assertEqual(t, entryIds(feed), "a,b")
+	if feed.Entries[0].Source != nil {
+		t.Error("Expected entries of the merged feed to keep no atom:source")
+	}
+	assertEqual(t, string(feed.Entries[1].Source.Id), "example.com/other")
+	assertEqual(t, string(feed.Entries[1].Source.Authors[0].Name), "Jane Doe")
+	assertEqual(t, time.Time(feed.Updated).Format("2006-01-02"), "2023-01-01")
+}
+
+func TestAtomFeed_MergeKeepsNewerUpdated(t *testing.T) {
+	feed := makeTestFeed(t)
+	other := makeTestFeed(t)
+	other.AddEntry(makeDatedEntry(t, "a", "2020-01-01"))
+
+	feed.Merge(other)
+
+	assertEqual(t, time.Time(feed.Updated).Format("2006-01-02"), "2022-07-04")
+}
+
+func TestAtomFeed_Dedupe(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddEntry(makeDatedEntry(t, "a", "2022-01-01"))
+	feed.AddEntry(makeDatedEntry(t, "b", "2022-01-01"))
+	feed.AddEntry(makeDatedEntry(t, "a", "2022-02-01"))
+	feed.AddEntry(makeDatedEntry(t, "b", "2021-01-01"))
+
+	feed.Dedupe()
+
+	assertEqual(t, entryIds(feed), "a,b")
+	assertEqual(t, time.Time(feed.Entries[0].Updated).Format("2006-01-02"), "2022-02-01")
+	assertEqual(t, time.Time(feed.Entries[1].Updated).Format("2006-01-02"), "2022-01-01")
+}
+
+func TestAtomFeed_SortByUpdated(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddEntry(makeDatedEntry(t, "a", "2022-01-01"))
+	feed.AddEntry(makeDatedEntry(t, "b", "2022-03-01"))
+	feed.AddEntry(makeDatedEntry(t, "c", "2022-01-01"))
+	feed.AddEntry(makeDatedEntry(t, "d", "2022-02-01"))
+
+	feed.SortByUpdated()
+
+	assertEqual(t, entryIds(feed), "b,d,a,c")
+}
+
+func TestAtomFeed_SortByPublished(t *testing.T) {
+	feed := makeTestFeed(t)
+	a := makeDatedEntry(t, "a", "2022-05-01")
+	a.SetPublished(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := makeDatedEntry(t, "b", "2022-02-01")
+	feed.AddEntry(a)
+	feed.AddEntry(b)
+
+	feed.SortByPublished()
+
+	assertEqual(t, entryIds(feed), "b,a")
+}
+
+func TestAtomFeed_Limit(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddEntry(makeDatedEntry(t, "a", "2022-01-01"))
+	feed.AddEntry(makeDatedEntry(t, "b", "2022-01-01"))
+	feed.AddEntry(makeDatedEntry(t, "c", "2022-01-01"))
+
+	if err := feed.Limit(-1); err == nil {
+		t.Error("Expected negative limit to throw error")
+	}
+	feed.Limit(5)
+	assertEqual(t, entryIds(feed), "a,b,c")
+	feed.Limit(2)
+	assertEqual(t, entryIds(feed), "a,b")
+}
+
+func TestAtomFeed_Filter(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddEntry(makeDatedEntry(t, "a", "2022-01-01"))
+	feed.AddEntry(makeDatedEntry(t, "b", "2022-03-01"))
+	feed.AddEntry(makeDatedEntry(t, "c", "2022-02-01"))
+
+	cutoff := time.Date(2022, 1, 15, 0, 0, 0, 0, time.UTC)
+	feed.Filter(func(entry AtomEntry) bool {
+		return time.Time(entry.Updated).After(cutoff)
+	})
+
+	assertEqual(t, entryIds(feed), "b,c")
+}
+
+func TestAtomFeed_RefreshUpdated(t *testing.T) {
+	feed := makeTestFeed(t)
+
+	feed.RefreshUpdated()
+	assertEqual(t, time.Time(feed.Updated).Format("2006-01-02"), "2022-07-04")
+
+	feed.AddEntry(makeDatedEntry(t, "a", "2020-01-01"))
+	feed.AddEntry(makeDatedEntry(t, "b", "2021-01-01"))
+
+	feed.RefreshUpdated()
+	assertEqual(t, time.Time(feed.Updated).Format("2006-01-02"), "2021-01-01")
+}
+
+func TestAtomFeed_NormalizeDates(t *testing.T) {
+	loc := time.FixedZone("", 10*60*60)
+	feed := makeTestFeed(t)
+	feed.SetUpdated(time.Date(2022, 7, 4, 22, 0, 0, 0, loc))
+	entry := makeDatedEntry(t, "a", "2022-07-04")
+	entry.SetPublished(time.Date(2022, 7, 4, 9, 30, 0, 500, loc))
+	feed.AddEntry(entry)
+
+	if err := feed.NormalizeDates(nil); err == nil {
+		t.Error("Expected nil location to throw error")
+	}
+	feed.NormalizeDates(time.UTC)
+
+	assertEqual(t, feed.Updated.String(), "2022-07-04T12:00:00Z")
+	assertEqual(t, feed.Entries[0].Updated.String(), "2022-07-04T00:00:00Z")
+	assertEqual(t, feed.Entries[0].Published.String(), "2022-07-03T23:30:00.0000005Z")
+}
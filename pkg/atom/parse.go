@@ -0,0 +1,16 @@
+package atom
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Parse decodes an Atom feed document into an AtomFeed. The document
+// is not validated; call Validate on the result if conformance matters.
+func Parse(r io.Reader) (*AtomFeed, error) {
+	var feed AtomFeed
+	if err := xml.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
@@ -0,0 +1,217 @@
+package atom
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_RoundTrip(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddAuthor("John Doe", "johndoe.com", "john@motors.com")
+	feed.AddLink("example.com", RelSelf)
+	feed.SetSubtitle("<em>Formatted</em> subtitle", "html")
+
+	entry := makeTestEntry(t)
+	entry.AddLink("example.com/entry/1", RelAlternate)
+	entry.AddCategory("cats", "", "")
+	entry.SetContent("<p>This is a paragraph</p>", "html")
+	feed.AddEntry(entry)
+
+	ref := feed.String()
+
+	parsed, err := Parse(strings.NewReader(ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, parsed.String(), ref)
+}
+
+func TestParse_RoundTripWithoutGenerator(t *testing.T) {
+	ref := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>example.com</id>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+</feed>`
+
+	parsed, err := Parse(strings.NewReader(ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, parsed.String(), ref)
+}
+
+func TestParse_Elements(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="text">Example Feed</title>
+  <link href="http://example.org/"/>
+  <updated>2003-12-13T18:30:02Z</updated>
+  <author>
+    <name>John Doe</name>
+  </author>
+  <id>urn:uuid:60a76c80-d399-11d9-b93C-0003939e0af6</id>
+  <generator uri="http://example.org/gen" version="1.0">Example Toolkit</generator>
+  <entry>
+    <title>Atom-Powered Robots Run Amok</title>
+    <link rel="alternate" href="http://example.org/2003/12/13/atom03"/>
+    <id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</id>
+    <updated>2003-12-13T18:30:02.25+01:00</updated>
+    <summary type="html">&lt;p&gt;Some text.&lt;/p&gt;</summary>
+  </entry>
+</feed>`
+
+	feed, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, string(feed.Id), "urn:uuid:60a76c80-d399-11d9-b93C-0003939e0af6")
+	assertEqual(t, feed.Title.Text, "Example Feed")
+	assertEqual(t, string(feed.Title.Type), "text")
+	assertEqual(t, string(feed.Authors[0].Name), "John Doe")
+	assertEqual(t, string(feed.Generator.Uri), "http://example.org/gen")
+	assertEqual(t, feed.Generator.Version, "1.0")
+	assertEqual(t, feed.Generator.Text, "Example Toolkit")
+
+	if len(feed.Links) != 1 || feed.Links[0].Rel != RelUnknown {
+		t.Error("Expected feed link without rel attribute to be parsed")
+	}
+
+	if len(feed.Entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(feed.Entries))
+	}
+	entry := feed.Entries[0]
+	if entry.Links[0].Rel != RelAlternate {
+		t.Error("Expected entry link to have rel=\"alternate\"")
+	}
+	assertEqual(t, string(entry.Summary.Type), "html")
+	assertEqual(t, entry.Summary.Text, "<p>Some text.</p>")
+
+	updated, _ := time.Parse(time.RFC3339, "2003-12-13T17:30:02.25Z")
+	if !time.Time(entry.Updated).Equal(updated) {
+		t.Errorf("Expected entry updated to equal %s", updated)
+	}
+}
+
+func TestParse_XhtmlText(t *testing.T) {
+	doc := `<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><em>Hi</em></div></title>
+</feed>`
+
+	feed, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, feed.Title.Text, "<em>Hi</em>")
+}
+
+func TestParse_InvalidDate(t *testing.T) {
+	doc := `<feed xmlns="http://www.w3.org/2005/Atom">
+  <updated>July 4th</updated>
+</feed>`
+
+	_, err := Parse(strings.NewReader(doc))
+	if err == nil {
+		t.Error("Expected atom:updated with an invalid date to throw error")
+	}
+}
+
+func TestParse_NotAtom(t *testing.T) {
+	doc := `<rss version="2.0"><channel></channel></rss>`
+
+	_, err := Parse(strings.NewReader(doc))
+	if err == nil {
+		t.Error("Expected non-Atom document to throw error")
+	}
+}
+
+func TestParse_Source(t *testing.T) {
+	source := makeTestFeed(t)
+	source.AddAuthor("John Doe", "", "")
+
+	feed := makeTestFeed(t)
+	entry := makeTestEntry(t)
+	entry.SetSource(CreateSource(source))
+	feed.AddEntry(entry)
+
+	ref := feed.String()
+
+	parsed, err := Parse(strings.NewReader(ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, string(parsed.Entries[0].Source.Authors[0].Name), "John Doe")
+	assertEqual(t, parsed.String(), ref)
+}
+
+func TestParse_XhtmlContent(t *testing.T) {
+	feed := makeTestFeed(t)
+	entry := makeTestEntry(t)
+	entry.SetContent(`<p>Hello &amp; <a href="example.com">world</a></p>`, "xhtml")
+	feed.AddEntry(entry)
+
+	ref := feed.String()
+
+	parsed, err := Parse(strings.NewReader(ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, parsed.Entries[0].Content.Text, `<p>Hello &amp; <a href="example.com">world</a></p>`)
+	assertEqual(t, parsed.String(), ref)
+}
+
+func TestParse_LinkRelations(t *testing.T) {
+	doc := `<feed xmlns="http://www.w3.org/2005/Atom">
+  <link rel="hub" href="https://hub.example.com/"/>
+  <link rel="http://example.com/rel/custom" href="https://example.com/custom"/>
+  <link rel="enclosure" type="audio/mpeg" length="1337" href="https://example.com/1.mp3"/>
+</feed>`
+
+	feed, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Links[0].Rel != RelHub {
+		t.Errorf("Expected rel=\"hub\", got %q", feed.Links[0].Rel)
+	}
+	assertEqual(t, string(feed.Links[1].Rel), "http://example.com/rel/custom")
+	assertEqual(t, string(feed.Links[2].Type), "audio/mpeg")
+	if feed.Links[2].Length != 1337 {
+		t.Errorf("Expected length 1337, got %d", feed.Links[2].Length)
+	}
+}
+
+func TestParse_LanguageAndBase(t *testing.T) {
+	doc := `<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en" xml:base="https://example.com/">
+  <title xml:lang="de">Hallo</title>
+  <entry xml:base="posts/">
+    <content type="xhtml" xml:lang="fr"><div xmlns="http://www.w3.org/1999/xhtml"><a href="1.html">Bonjour</a></div></content>
+  </entry>
+</feed>`
+
+	feed, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, string(feed.Lang), "en")
+	assertEqual(t, string(feed.Base), "https://example.com/")
+	assertEqual(t, string(feed.Title.Lang), "de")
+	assertEqual(t, string(feed.Entries[0].Base), "posts/")
+	assertEqual(t, string(feed.Entries[0].Content.Lang), "fr")
+
+	ref := feed.String()
+	parsed, err := Parse(strings.NewReader(ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, parsed.String(), ref)
+}
@@ -0,0 +1,160 @@
+package atom
+
+import (
+	"fmt"
+	"time"
+)
+
+// Repair fixes common problems which make a feed invalid and describes
+// each change it made. Feeds without authors are given the title as
+// author, missing ids are derived from the feed, empty optional text is
+// removed, people without a name are named after their uri or email, and
+// dates are clamped so nothing is updated in the future or published after
+// it was updated. Problems which cannot be repaired are left for Validate
+// to report.
+func (feed *AtomFeed) Repair() []string {
+	return feed.repair(time.Now())
+}
+
+func (feed *AtomFeed) repair(now time.Time) []string {
+	var repairs []string
+	path := "feed"
+
+	if len(feed.Id) == 0 {
+		feed.Id = AtomID(NewUUIDv5(NamespaceURL, repairName(feed.Links, feed.Title)).URN())
+		repairs = append(repairs, fmt.Sprintf("%s: added missing id %s", path, feed.Id))
+	}
+
+	if feed.Subtitle != nil && len(feed.Subtitle.Text) == 0 {
+		feed.Subtitle = nil
+		repairs = append(repairs, fmt.Sprintf("%s: removed empty subtitle", path))
+	}
+	if feed.Rights != nil && len(feed.Rights.Text) == 0 {
+		feed.Rights = nil
+		repairs = append(repairs, fmt.Sprintf("%s: removed empty rights", path))
+	}
+
+	for i := range feed.Authors {
+		person := (*AtomPersonConstruct)(&feed.Authors[i])
+		repairs = append(repairs, person.repairMissingName(childPath(path, "author", i))...)
+	}
+	for i := range feed.Contributors {
+		person := (*AtomPersonConstruct)(&feed.Contributors[i])
+		repairs = append(repairs, person.repairMissingName(childPath(path, "contributor", i))...)
+	}
+
+	if len(feed.Authors) == 0 && needsFeedAuthor(feed.Entries) {
+		name := AtomTextConstruct(feed.Title).PlainText()
+		if len(name) == 0 {
+			name = string(feed.Id)
+		}
+		feed.AddAuthor(name, "", "")
+		repairs = append(repairs, fmt.Sprintf("%s: added author '%s' from title", path, name))
+	}
+
+	for i := range feed.Entries {
+		entry := &feed.Entries[i]
+		entryPath := childPath(path, "entry", i)
+
+		if len(entry.Id) == 0 {
+			// ids are scoped to the feed so equal entries of different feeds
+			// stay distinct
+			name := string(feed.Id) + " " + repairName(entry.Links, entry.Title)
+			entry.Id = AtomID(NewUUIDv5(NamespaceURL, name).URN())
+			repairs = append(repairs, fmt.Sprintf("%s: added missing id %s", entryPath, entry.Id))
+		}
+
+		if entry.Summary != nil && len(entry.Summary.Text) == 0 {
+			entry.Summary = nil
+			repairs = append(repairs, fmt.Sprintf("%s: removed empty summary", entryPath))
+		}
+		if entry.Rights != nil && len(entry.Rights.Text) == 0 {
+			entry.Rights = nil
+			repairs = append(repairs, fmt.Sprintf("%s: removed empty rights", entryPath))
+		}
+
+		for j := range entry.Authors {
+			person := (*AtomPersonConstruct)(&entry.Authors[j])
+			repairs = append(repairs, person.repairMissingName(childPath(entryPath, "author", j))...)
+		}
+		for j := range entry.Contributors {
+			person := (*AtomPersonConstruct)(&entry.Contributors[j])
+			repairs = append(repairs, person.repairMissingName(childPath(entryPath, "contributor", j))...)
+		}
+
+		repairs = append(repairs, entry.repairDates(entryPath, time.Time(feed.Updated), now)...)
+	}
+
+	if time.Time(feed.Updated).IsZero() {
+		updated := now
+		if newest, ok := newestUpdated(feed.Entries); ok {
+			updated = newest
+		}
+		feed.Updated = AtomDate(updated)
+		repairs = append(repairs, fmt.Sprintf("%s: set missing updated to %s", path, updated.Format(time.RFC3339)))
+	} else if time.Time(feed.Updated).After(now) {
+		feed.Updated = AtomDate(now)
+		repairs = append(repairs, fmt.Sprintf("%s: clamped updated in the future to %s", path, now.Format(time.RFC3339)))
+	}
+
+	return repairs
+}
+
+func (entry *AtomEntry) repairDates(path string, feedUpdated time.Time, now time.Time) []string {
+	var repairs []string
+
+	updated := time.Time(entry.Updated)
+	if updated.IsZero() {
+		updated = feedUpdated
+		if entry.Published != nil {
+			updated = time.Time(*entry.Published)
+		}
+		if updated.IsZero() || updated.After(now) {
+			updated = now
+		}
+		repairs = append(repairs, fmt.Sprintf("%s: set missing updated to %s", path, updated.Format(time.RFC3339)))
+	} else if updated.After(now) {
+		updated = now
+		repairs = append(repairs, fmt.Sprintf("%s: clamped updated in the future to %s", path, now.Format(time.RFC3339)))
+	}
+	entry.Updated = AtomDate(updated)
+
+	if entry.Published != nil && time.Time(*entry.Published).After(updated) {
+		published := AtomDate(updated)
+		entry.Published = &published
+		repairs = append(repairs, fmt.Sprintf("%s: clamped published to updated", path))
+	}
+
+	return repairs
+}
+
+// repairMissingName names a person without a name after their uri or
+// email, or as unknown when they have neither.
+func (person *AtomPersonConstruct) repairMissingName(path string) []string {
+	if len(person.Name) > 0 {
+		return nil
+	}
+
+	switch {
+	case len(person.Uri) > 0:
+		person.Name = AtomName(person.Uri)
+	case len(person.Email) > 0:
+		person.Name = AtomName(person.Email)
+	default:
+		person.Name = "Unknown"
+	}
+	return []string{fmt.Sprintf("%s: added missing name '%s'", path, person.Name)}
+}
+
+// repairName identifies an element without an id by its alternate or self
+// link, falling back to its title.
+func repairName(links []AtomLink, title AtomTitle) string {
+	for _, rel := range []AtomRelType{RelSelf, RelAlternate, RelUnknown} {
+		for _, link := range links {
+			if link.Rel == rel && len(link.Href) > 0 {
+				return string(link.Href)
+			}
+		}
+	}
+	return AtomTextConstruct(title).PlainText()
+}
@@ -0,0 +1,82 @@
+package atom
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAtomFeed_Repair(t *testing.T) {
+	now := time.Date(2022, 7, 10, 0, 0, 0, 0, time.UTC)
+	feed := makeTestFeed(t)
+
+	missingId := makeTestEntry(t)
+	missingId.Id = ""
+	missingId.AddLink("example.com/entry/1", RelAlternate)
+	feed.AddEntry(missingId)
+
+	future := makeDatedEntry(t, "example.com/entry/2", "2022-08-01")
+	future.SetPublished(time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC))
+	future.SetContent("Hello", "text")
+	feed.AddEntry(future)
+
+	if feed.Report().Valid() {
+		t.Fatal("Expected feed to be invalid before repair")
+	}
+
+	repairs := feed.repair(now)
+	ref := `feed: added author 'My Website' from title
+feed/entry[0]: added missing id ` + NewUUIDv5(NamespaceURL, "example.com example.com/entry/1").URN() + `
+feed/entry[1]: clamped updated in the future to 2022-07-10T00:00:00Z
+feed/entry[1]: clamped published to updated`
+
+	assertEqual(t, strings.Join(repairs, "\n"), ref)
+	if !feed.Report().Valid() {
+		t.Errorf("Expected repaired feed to be valid, got %s", feed.Report())
+	}
+}
+
+func TestAtomFeed_RepairValidFeed(t *testing.T) {
+	feed := makeValidTestFeed(t)
+
+	repairs := feed.Repair()
+
+	if len(repairs) > 0 {
+		t.Errorf("Expected valid feed to need no repairs, got %v", repairs)
+	}
+}
+
+func TestAtomFeed_RepairEmptyConstructs(t *testing.T) {
+	now := time.Date(2022, 7, 10, 0, 0, 0, 0, time.UTC)
+	feed, err := NewFeedBuilder("example.com", "My Website", now).
+		Author("", "https://example.com/about", "").
+		Contributor("", "", "").
+		Subtitle("", "text").
+		Copyright("", "text").
+		BuildUnvalidated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := makeTestEntry(t)
+	entry.AddLink("example.com/entry/1", RelAlternate)
+	entry.SetSummary("", "html")
+	entry.AddAuthor("", "", "john@example.com")
+	feed.AddEntry(entry)
+
+	if feed.Report().Valid() {
+		t.Fatal("Expected feed to be invalid before repair")
+	}
+
+	repairs := feed.repair(now)
+	ref := `feed: removed empty subtitle
+feed: removed empty rights
+feed/author[0]: added missing name 'https://example.com/about'
+feed/contributor[0]: added missing name 'Unknown'
+feed/entry[0]: removed empty summary
+feed/entry[0]/author[0]: added missing name 'john@example.com'`
+
+	assertEqual(t, strings.Join(repairs, "\n"), ref)
+	if report := feed.Report(); !report.Valid() {
+		t.Errorf("Expected repaired feed to be valid, got %s", report)
+	}
+}
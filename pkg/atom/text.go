@@ -0,0 +1,25 @@
+package atom
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var tagMatcher = regexp.MustCompile("<[^>]*>")
+
+// PlainText returns the text construct without markup.
+func (text AtomTextConstruct) PlainText() string {
+	if text.Type == "html" || text.Type == "xhtml" {
+		return strings.TrimSpace(html.UnescapeString(tagMatcher.ReplaceAllString(text.Text, "")))
+	}
+	return text.Text
+}
+
+// HTML returns the text construct as html markup.
+func (text AtomTextConstruct) HTML() string {
+	if text.Type == "html" || text.Type == "xhtml" {
+		return text.Text
+	}
+	return html.EscapeString(text.Text)
+}
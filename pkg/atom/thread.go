@@ -0,0 +1,74 @@
+// see RFC 4685
+
+package atom
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// s3
+
+// AddInReplyTo marks the entry as a response to the resource identified by
+// ref, usually the atom:id of another entry. href, mediaType and source are
+// optional and locate the resource and the feed containing it.
+func (entry *AtomEntry) AddInReplyTo(ref string, href string, mediaType string, source string) error {
+	if len(ref) == 0 {
+		return errors.New("thr:in-reply-to must have a ref attribute")
+	}
+
+	ext := CreateExtension(ThreadingNamespace, "in-reply-to", "")
+	ext.SetAttr("ref", ref)
+	if len(href) > 0 {
+		ext.SetAttr("href", href)
+	}
+	if len(mediaType) > 0 {
+		ext.SetAttr("type", mediaType)
+	}
+	if len(source) > 0 {
+		ext.SetAttr("source", source)
+	}
+	return entry.AddExtension(ext)
+}
+
+// s4
+
+// AddRepliesLink links the entry to a resource containing its responses.
+// count is omitted when negative and updated is omitted when zero.
+func (entry *AtomEntry) AddRepliesLink(href string, mediaType string, count int, updated time.Time) error {
+	link := createLink(href, RelReplies, LinkOptions{Type: mediaType})
+	if count >= 0 {
+		link.Attrs = append(link.Attrs, xml.Attr{
+			Name:  xml.Name{Space: ThreadingNamespace.URI, Local: "count"},
+			Value: strconv.Itoa(count),
+		})
+	}
+	if !updated.IsZero() {
+		link.Attrs = append(link.Attrs, xml.Attr{
+			Name:  xml.Name{Space: ThreadingNamespace.URI, Local: "updated"},
+			Value: AtomDate(updated).String(),
+		})
+	}
+	entry.Links = append(entry.Links, link)
+	return nil
+}
+
+// s5
+
+// SetTotal sets the total number of responses to the entry.
+func (entry *AtomEntry) SetTotal(total int) error {
+	if total < 0 {
+		return errors.New("thr:total cannot be negative")
+	}
+
+	ext := CreateExtension(ThreadingNamespace, "total", strconv.Itoa(total))
+	for i, existing := range entry.Extensions {
+		if existing.XMLName == ext.XMLName {
+			entry.Extensions[i] = ext
+			return nil
+		}
+	}
+	return entry.AddExtension(ext)
+}
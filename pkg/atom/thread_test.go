@@ -0,0 +1,66 @@
+package atom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// s3
+
+func TestAtomEntry_AddInReplyTo(t *testing.T) {
+	entry := makeTestEntry(t)
+	ref :=
+		`<entry xmlns:thr="http://purl.org/syndication/thread/1.0">
+  <id>example.com/entry/1</id>
+  <title>Entry 1</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+  <thr:in-reply-to ref="example.com/entry/0" href="example.com/0.html" type="text/html"></thr:in-reply-to>
+</entry>`
+
+	if err := entry.AddInReplyTo("", "", "", ""); err == nil {
+		t.Error("Expected thr:in-reply-to without a ref to throw error")
+	}
+	if err := entry.AddInReplyTo("example.com/entry/0", "example.com/0.html", "text/html", ""); err != nil {
+		t.Error(err)
+	}
+
+	assertEqual(t, entry.String(), ref)
+}
+
+// s4 and s5
+
+func TestAtomEntry_Replies(t *testing.T) {
+	feed := makeTestFeed(t)
+	entry := makeTestEntry(t)
+	updated := time.Date(2022, 7, 5, 0, 0, 0, 0, time.UTC)
+	ref :=
+		fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:thr="http://purl.org/syndication/thread/1.0">
+  %s
+  <id>example.com</id>
+  <title>My Website</title>
+  <updated>2022-07-04T12:34:00Z</updated>
+  <entry>
+    <id>example.com/entry/1</id>
+    <link href="example.com/entry/1/comments" rel="replies" type="application/atom+xml" thr:count="3" thr:updated="2022-07-05T00:00:00Z"></link>
+    <title>Entry 1</title>
+    <updated>2022-07-04T12:34:00Z</updated>
+    <thr:total>3</thr:total>
+  </entry>
+</feed>`, getGenerator())
+
+	entry.AddRepliesLink("example.com/entry/1/comments", "application/atom+xml", 3, updated)
+	entry.SetTotal(2)
+	entry.SetTotal(3)
+	feed.AddEntry(entry)
+
+	assertEqual(t, feed.String(), ref)
+
+	parsed, err := Parse(strings.NewReader(ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, parsed.String(), ref)
+}
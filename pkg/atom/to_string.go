@@ -1,16 +1,17 @@
 package atom
 
-import "encoding/xml"
+import "strings"
 
 func (feed AtomFeed) String() string {
+	var out strings.Builder
 	// silently ignore errors
-	out, _ := xml.MarshalIndent(feed, "", "  ")
-	data := []byte(xml.Header + string(out))
-	return string(data)
+	feed.Encode(&out)
+	return out.String()
 }
 
 func (entry AtomEntry) String() string {
+	var out strings.Builder
 	// silently ignore errors
-	out, _ := xml.MarshalIndent(entry, "", "  ")
-	return string(out)
+	entry.Encode(&out)
+	return out.String()
 }
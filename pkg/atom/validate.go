@@ -3,6 +3,8 @@ package atom
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -16,145 +18,445 @@ func FeedError(message string, rfcSection string) error {
 	return errors.New(str)
 }
 
+// report
+
+type ValidationSeverity int
+
+const (
+	SeverityMust   ValidationSeverity = iota // violates an absolute requirement
+	SeverityShould                           // violates a recommendation
+)
+
+func (severity ValidationSeverity) String() string {
+	if severity == SeverityShould {
+		return "SHOULD"
+	}
+	return "MUST"
+}
+
+type ValidationIssue struct {
+	Severity ValidationSeverity
+	Path     string // element path such as "feed/entry[3]/link[0]"
+	Message  string
+	Section  string // RFC 4287 section
+}
+
+func (issue ValidationIssue) Error() string {
+	message := issue.Message
+	if len(issue.Path) > 0 {
+		message = issue.Path + ": " + message
+	}
+	return FeedError(message, issue.Section).Error()
+}
+
+// ValidationReport lists every violation found in a feed or entry rather
+// than only the first one.
+type ValidationReport struct {
+	Issues []ValidationIssue
+}
+
+func (report *ValidationReport) must(path string, message string, rfcSection string) {
+	report.Issues = append(report.Issues, ValidationIssue{
+		Severity: SeverityMust,
+		Path:     path,
+		Message:  message,
+		Section:  rfcSection,
+	})
+}
+
+func (report *ValidationReport) should(path string, message string, rfcSection string) {
+	report.Issues = append(report.Issues, ValidationIssue{
+		Severity: SeverityShould,
+		Path:     path,
+		Message:  message,
+		Section:  rfcSection,
+	})
+}
+
+// Valid reports whether no absolute requirements are violated.
+func (report ValidationReport) Valid() bool {
+	for _, issue := range report.Issues {
+		if issue.Severity == SeverityMust {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns the first issue of any severity, or nil.
+func (report ValidationReport) Err() error {
+	if len(report.Issues) == 0 {
+		return nil
+	}
+	return report.Issues[0]
+}
+
+func (report ValidationReport) String() string {
+	var str strings.Builder
+	for _, issue := range report.Issues {
+		str.WriteString(issue.Severity.String() + " " + issue.Error() + "\n")
+	}
+	return str.String()
+}
+
+func childPath(path string, element string, index int) string {
+	if index >= 0 {
+		element = fmt.Sprintf("%s[%d]", element, index)
+	}
+	if len(path) == 0 {
+		return element
+	}
+	return path + "/" + element
+}
+
 // s3.1
 
 func (text AtomTextConstruct) Validate() error {
+	var report ValidationReport
+	text.validate(&report, "")
+	return report.Err()
+}
+
+func (text AtomTextConstruct) validate(report *ValidationReport, path string) {
 	if len(text.Text) == 0 {
-		return FeedError("text constructs must have textual content", "3.1")
+		report.must(path, "text constructs must have textual content", "3.1")
+	}
+	if text.Type == "xhtml" && checkXHTML(text.Text) != nil {
+		report.must(path, "xhtml text constructs must contain well-formed markup", "3.1.1.3")
 	}
-	return nil
 }
 
 // s3.2
 
 func (person AtomPersonConstruct) Validate() error {
+	var report ValidationReport
+	person.validate(&report, "")
+	return report.Err()
+}
+
+func (person AtomPersonConstruct) validate(report *ValidationReport, path string) {
 	if len(person.Name) == 0 {
-		return FeedError("person constructs must contain exactly one atom:name element", "3.2.1")
+		report.must(path, "person constructs must contain exactly one atom:name element", "3.2.1")
 	}
-	return nil
 }
 
 // s4.1.1
 
+// Validate returns the first problem found in the feed, including
+// recommendations. Use Report to list every problem.
 func (feed AtomFeed) Validate() error {
-	if len(feed.Authors) == 0 {
-		return FeedError("atom:feed elements must contain at least one author (simplified)", "4.1.1")
+	return feed.Report().Err()
+}
+
+func (feed AtomFeed) Report() ValidationReport {
+	var report ValidationReport
+	path := "feed"
+
+	for i, author := range feed.Authors {
+		AtomPersonConstruct(author).validate(&report, childPath(path, "author", i))
 	}
-	for _, author := range feed.Authors {
-		err := AtomPersonConstruct(author).Validate()
-		if err != nil {
-			return err
+	if len(feed.Authors) == 0 {
+		// without entries the requirement holds vacuously, but readers
+		// have no author to show
+		if len(feed.Entries) == 0 {
+			report.should(path, "atom:feed elements should contain at least one author", "4.1.1")
+		}
+		for i, entry := range feed.Entries {
+			// authors of the source feed apply to entries without authors
+			if len(entry.Authors) == 0 && (entry.Source == nil || len(entry.Source.Authors) == 0) {
+				report.must(childPath(path, "entry", i), "atom:entry elements must contain at least one author when the feed contains none", "4.1.1")
+			}
 		}
 	}
 
-	for _, cat := range feed.Categories {
-		err := cat.Validate()
-		if err != nil {
-			return err
-		}
+	for i, cat := range feed.Categories {
+		cat.validate(&report, childPath(path, "category", i))
 	}
 
-	for _, contrib := range feed.Contributors {
-		err := AtomPersonConstruct(contrib).Validate()
-		if err != nil {
-			return err
-		}
+	for i, contrib := range feed.Contributors {
+		AtomPersonConstruct(contrib).validate(&report, childPath(path, "contributor", i))
 	}
 
 	if len(feed.Id) == 0 {
-		return FeedError("atom:feed elements must contain exactly one id", "4.1.1")
+		report.must(path, "atom:feed elements must contain exactly one id", "4.1.1")
 	}
 
-	// ensure at least one link exists with rel="self"
-	hasSelfLink := false
-	for _, link := range feed.Links {
-		err := link.Validate()
-		if err != nil {
-			return err
-		}
+	validateLinks(&report, path, "atom:feed", feed.Links, "4.1.1")
 
+	// ensure exactly one link exists with rel="self"
+	selfLinks := 0
+	for _, link := range feed.Links {
 		if link.Rel == RelSelf {
-			if hasSelfLink {
-				return FeedError("atom:feed elements must not contain more than one link with rel=\"self\" (simplified)", "4.1.1")
-			}
-			hasSelfLink = true
+			selfLinks++
 		}
 	}
-	if !hasSelfLink {
-		return FeedError("atom:feed elements should contain one link with rel=\"self\" (simplified)", "4.1.1")
+	if selfLinks == 0 {
+		report.should(path, "atom:feed elements should contain one link with rel=\"self\"", "4.1.1")
+	} else if selfLinks > 1 {
+		report.should(path, "atom:feed elements should not contain more than one link with rel=\"self\"", "4.1.1")
+	}
+
+	if feed.Rights != nil {
+		AtomTextConstruct(*feed.Rights).validate(&report, childPath(path, "rights", -1))
+	}
+
+	if feed.Subtitle != nil {
+		AtomTextConstruct(*feed.Subtitle).validate(&report, childPath(path, "subtitle", -1))
 	}
 
 	if len(feed.Title.Text) == 0 {
-		return FeedError("atom:feed elements must contain exactly one title element", "4.1.1")
+		report.must(path, "atom:feed elements must contain exactly one title element", "4.1.1")
 	}
 
 	if time.Time(feed.Updated).IsZero() {
-		return FeedError("atom:feed elements must contain exactly one valid updated element", "4.1.1")
+		report.must(path, "atom:feed elements must contain exactly one valid updated element", "4.1.1")
 	}
 
-	for _, entry := range feed.Entries {
-		err := entry.Validate()
-		if err != nil {
-			return err
+	// entries may share an id as long as they are different revisions
+	type revision struct {
+		id      AtomID
+		updated time.Time
+	}
+	revisions := make(map[revision]bool)
+	for i, entry := range feed.Entries {
+		entryPath := childPath(path, "entry", i)
+		if len(entry.Id) > 0 {
+			key := revision{entry.Id, time.Time(entry.Updated).UTC()}
+			if revisions[key] {
+				report.should(entryPath, "atom:entry elements with the same id should have different updated elements", "4.1.1")
+			}
+			revisions[key] = true
 		}
+		entry.validate(&report, entryPath)
 	}
 
-	return nil
+	return report
 }
 
 // s4.1.2
 
+// Validate returns the first problem found in the entry, including
+// recommendations. Use Report to list every problem.
 func (entry AtomEntry) Validate() error {
-	for _, author := range entry.Authors {
-		err := AtomPersonConstruct(author).Validate()
-		if err != nil {
-			return err
-		}
+	return entry.Report().Err()
+}
+
+func (entry AtomEntry) Report() ValidationReport {
+	var report ValidationReport
+	entry.validate(&report, "entry")
+	return report
+}
+
+func (entry AtomEntry) validate(report *ValidationReport, path string) {
+	for i, author := range entry.Authors {
+		AtomPersonConstruct(author).validate(report, childPath(path, "author", i))
 	}
 
-	for _, cat := range entry.Categories {
-		err := cat.Validate()
-		if err != nil {
-			return err
-		}
+	for i, cat := range entry.Categories {
+		cat.validate(report, childPath(path, "category", i))
 	}
 
-	for _, contrib := range entry.Contributors {
-		err := AtomPersonConstruct(contrib).Validate()
-		if err != nil {
-			return err
+	if entry.Content != nil {
+		entry.Content.validate(report, childPath(path, "content", -1))
+
+		if len(entry.Content.Src) > 0 && entry.Summary == nil {
+			report.must(path, "atom:entry elements must contain a summary when the content has a src attribute", "4.1.2")
 		}
 	}
 
+	for i, contrib := range entry.Contributors {
+		AtomPersonConstruct(contrib).validate(report, childPath(path, "contributor", i))
+	}
+
 	if len(entry.Id) == 0 {
-		return FeedError("atom:entry elements must contain exactly one id", "4.1.2")
+		report.must(path, "atom:entry elements must contain exactly one id", "4.1.2")
+	}
+
+	validateLinks(report, path, "atom:entry", entry.Links, "4.1.2")
+
+	if entry.Content == nil {
+		hasAlternateLink := false
+		for _, link := range entry.Links {
+			if link.Rel == RelAlternate || link.Rel == RelUnknown {
+				hasAlternateLink = true
+			}
+		}
+		if !hasAlternateLink {
+			report.must(path, "atom:entry elements without content must contain at least one link with rel=\"alternate\"", "4.1.2")
+		}
+	}
+
+	if entry.Rights != nil {
+		AtomTextConstruct(*entry.Rights).validate(report, childPath(path, "rights", -1))
+	}
+
+	if entry.Source != nil {
+		entry.Source.validate(report, childPath(path, "source", -1))
+	}
+
+	if entry.Summary != nil {
+		AtomTextConstruct(*entry.Summary).validate(report, childPath(path, "summary", -1))
 	}
 
 	if len(entry.Title.Text) == 0 {
-		return FeedError("atom:feed elements must contain exactly one title element", "4.1.1")
+		report.must(path, "atom:entry elements must contain exactly one title element", "4.1.2")
 	}
 
 	if time.Time(entry.Updated).IsZero() {
-		return FeedError("atom:feed elements must contain exactly one valid updated element", "4.1.1")
+		report.must(path, "atom:entry elements must contain exactly one valid updated element", "4.1.2")
+	} else if entry.Published != nil && entry.Published.After(entry.Updated) {
+		report.should(path, "atom:published should not be later than atom:updated", "4.2.9")
+	}
+}
+
+// s4.1.3
+
+func (content AtomContent) validate(report *ValidationReport, path string) {
+	if len(content.Src) > 0 && len(content.Text) > 0 {
+		report.must(path, "atom:content elements with a src attribute must be empty", "4.1.3.2")
+	}
+	if content.Type == "xhtml" && checkXHTML(content.Text) != nil {
+		report.must(path, "xhtml content must contain well-formed markup", "4.1.3.3")
+	}
+}
+
+// s4.2.11
+
+func (source AtomSource) Validate() error {
+	var report ValidationReport
+	source.validate(&report, "source")
+	return report.Err()
+}
+
+func (source AtomSource) validate(report *ValidationReport, path string) {
+	for i, author := range source.Authors {
+		AtomPersonConstruct(author).validate(report, childPath(path, "author", i))
+	}
+
+	for i, cat := range source.Categories {
+		cat.validate(report, childPath(path, "category", i))
+	}
+
+	for i, contrib := range source.Contributors {
+		AtomPersonConstruct(contrib).validate(report, childPath(path, "contributor", i))
+	}
+
+	if len(source.Id) == 0 {
+		report.should(path, "atom:source elements should contain the id of the source feed", "4.2.11")
+	}
+
+	for i, link := range source.Links {
+		link.validate(report, childPath(path, "link", i))
+	}
+
+	if source.Rights != nil {
+		AtomTextConstruct(*source.Rights).validate(report, childPath(path, "rights", -1))
 	}
 
-	return nil
+	if source.Subtitle != nil {
+		AtomTextConstruct(*source.Subtitle).validate(report, childPath(path, "subtitle", -1))
+	}
+
+	if source.Title == nil {
+		report.should(path, "atom:source elements should contain the title of the source feed", "4.2.11")
+	} else {
+		AtomTextConstruct(*source.Title).validate(report, childPath(path, "title", -1))
+	}
+
+	if source.Updated == nil || time.Time(*source.Updated).IsZero() {
+		report.should(path, "atom:source elements should contain the updated date of the source feed", "4.2.11")
+	}
 }
 
 // s4.2.2
 
 func (category AtomCategory) Validate() error {
+	var report ValidationReport
+	category.validate(&report, "")
+	return report.Err()
+}
+
+func (category AtomCategory) validate(report *ValidationReport, path string) {
 	if len(category.Term) == 0 {
-		return FeedError("atom:category elements must have a term attribute", "4.2.2.1")
+		report.must(path, "atom:category elements must have a term attribute", "4.2.2.1")
 	}
-	return nil
 }
 
 // s4.2.7
 
 func (link AtomLink) Validate() error {
+	var report ValidationReport
+	link.validate(&report, "")
+	return report.Err()
+}
+
+func (link AtomLink) validate(report *ValidationReport, path string) {
 	if len(link.Href) == 0 {
-		return FeedError("atom:link elements must have an href attribute", "4.2.7.1")
+		report.must(path, "atom:link elements must have an href attribute", "4.2.7.1")
+	}
+
+	if len(link.Rel) > 0 {
+		rel := string(link.Rel)
+		if strings.Contains(rel, ":") {
+			iri, err := url.Parse(rel)
+			if err != nil || !iri.IsAbs() {
+				report.must(path, "atom:link rel attributes must be a simple name or an IRI", "4.2.7.2")
+			}
+		} else if strings.ContainsAny(rel, "/?#[]@ \t\n") {
+			report.must(path, "atom:link rel attributes must be a simple name or an IRI", "4.2.7.2")
+		} else if !registeredRels[link.Rel] {
+			report.should(path, "atom:link rel attributes should be registered with IANA or be an IRI", "4.2.7.2")
+		}
+	}
+
+	if link.Rel == RelEnclosure && link.Length == 0 {
+		report.should(path, "atom:link elements with rel=\"enclosure\" should have a length attribute", "4.2.7.6")
+	}
+}
+
+// a subset of the IANA link relations registry
+var registeredRels = map[AtomRelType]bool{
+	RelAlternate:   true,
+	RelRelated:     true,
+	RelSelf:        true,
+	RelEnclosure:   true,
+	RelVia:         true,
+	RelFirst:       true,
+	RelLast:        true,
+	RelNext:        true,
+	RelPrevious:    true,
+	RelCurrent:     true,
+	RelPrevArchive: true,
+	RelNextArchive: true,
+	RelReplies:     true,
+	RelPayment:     true,
+	RelHub:         true,
+	RelLicense:     true,
+	RelEdit:        true,
+	"edit-media":   true,
+	"describedby":  true,
+	"icon":         true,
+	"prev":         true,
+	"preview":      true,
+	"search":       true,
+	"service":      true,
+	"up":           true,
+}
+
+func validateLinks(report *ValidationReport, path string, element string, links []AtomLink, rfcSection string) {
+	// links without a rel attribute are alternate links
+	alternates := make(map[string]bool)
+	for i, link := range links {
+		linkPath := childPath(path, "link", i)
+		link.validate(report, linkPath)
+
+		if link.Rel != RelAlternate && link.Rel != RelUnknown {
+			continue
+		}
+		key := string(link.Type) + " " + string(link.HrefLang)
+		if alternates[key] {
+			report.must(linkPath, element+" elements must not contain more than one link with rel=\"alternate\" with the same type and hreflang", rfcSection)
+		}
+		alternates[key] = true
 	}
-	return nil
 }
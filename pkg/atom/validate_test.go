@@ -18,6 +18,18 @@ func TestValidateAtomTextConstruct_MissingText(t *testing.T) {
 	}
 }
 
+func TestValidateAtomTextConstruct_MalformedXhtml(t *testing.T) {
+	text := AtomTextConstruct{
+		Type: "xhtml",
+		Text: "<p>Unclosed paragraph",
+	}
+
+	err := text.Validate()
+	if err == nil {
+		t.Error("Expected atomTextConstruct with malformed xhtml to throw error")
+	}
+}
+
 // s3.1
 
 func TestValidateAtomPersonConstruct_MissingName(t *testing.T) {
@@ -51,6 +63,15 @@ func TestValidateAtomFeed_MissingAuthor(t *testing.T) {
 	}
 }
 
+func TestValidateAtomFeed_EmptyFeedWithoutAuthor(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddLink("https://example.com", RelSelf)
+
+	if report := feed.Report(); !report.Valid() {
+		t.Errorf("Expected atom:feed with no authors and no entries to be valid, got:\n%s", report)
+	}
+}
+
 func TestValidateAtomFeed_MissingId(t *testing.T) {
 	date, dateErr := time.Parse("2006-01-02 15:04", "2022-07-04 12:34")
 	if dateErr != nil {
@@ -253,8 +274,15 @@ func TestValidateAtomEntry_ValidEntry(t *testing.T) {
 	if dateErr != nil {
 		t.Error("Error: unable parse datetime")
 	}
+	links := []AtomLink{
+		AtomLink{
+			Href: "example.com/entry1",
+			Rel:  RelAlternate,
+		},
+	}
 	entry := AtomEntry{
-		Id: "example.com/entry1",
+		Id:    "example.com/entry1",
+		Links: links,
 		Title: AtomTitle{
 			Text: "Entry 1",
 		},
@@ -266,6 +294,39 @@ func TestValidateAtomEntry_ValidEntry(t *testing.T) {
 	}
 }
 
+// s4.2.11
+
+func TestValidateAtomSource_ValidSource(t *testing.T) {
+	source := CreateSource(makeValidTestFeed(t))
+	err := source.Validate()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateAtomSource_MissingMetadata(t *testing.T) {
+	source := AtomSource{}
+	err := source.Validate()
+	if err == nil {
+		t.Error("Expected atom:source without id, title or updated to throw error")
+	}
+}
+
+func TestValidateAtomSource_InheritAuthors(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddLink("example.com", RelSelf)
+
+	entry := makeTestEntry(t)
+	entry.AddLink("example.com/entry/1", RelAlternate)
+	entry.SetSource(CreateSource(makeValidTestFeed(t)))
+	feed.AddEntry(entry)
+
+	err := feed.Validate()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
 // s4.2.2
 
 func TestValidateAtomCategory_MissingTerm(t *testing.T) {
@@ -297,3 +358,192 @@ func TestValidateAtomLink_MissingHref(t *testing.T) {
 		t.Error("Expected atom:link to have an href attribute")
 	}
 }
+
+// report
+
+func makeValidTestFeed(t *testing.T) *AtomFeed {
+	feed := makeTestFeed(t)
+	feed.AddAuthor("John Doe", "", "")
+	feed.AddLink("example.com", RelSelf)
+	return feed
+}
+
+func assertIssue(t *testing.T, report ValidationReport, path string, severity ValidationSeverity) {
+	for _, issue := range report.Issues {
+		if issue.Path == path && issue.Severity == severity {
+			return
+		}
+	}
+	t.Errorf("Expected %s issue at %s, got:\n%s", severity, path, report)
+}
+
+func TestValidationReport_ValidFeed(t *testing.T) {
+	feed := makeValidTestFeed(t)
+	entry := makeTestEntry(t)
+	entry.AddLink("example.com/entry/1", RelAlternate)
+	feed.AddEntry(entry)
+
+	report := feed.Report()
+	if len(report.Issues) > 0 {
+		t.Errorf("Expected no issues, got:\n%s", report)
+	}
+}
+
+func TestValidationReport_MultipleIssues(t *testing.T) {
+	feed := AtomFeed{
+		Links: []AtomLink{
+			AtomLink{},
+		},
+	}
+
+	report := feed.Report()
+	if len(report.Issues) != 6 {
+		t.Errorf("Expected 6 issues, got:\n%s", report)
+	}
+	if report.Valid() {
+		t.Error("Expected report with MUST issues to be invalid")
+	}
+	assertIssue(t, report, "feed/link[0]", SeverityMust)
+	assertIssue(t, report, "feed", SeverityShould)
+}
+
+func TestValidationReport_EntryAuthors(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddLink("example.com", RelSelf)
+
+	entry1 := makeTestEntry(t)
+	entry1.AddLink("example.com/entry/1", RelAlternate)
+	entry1.Authors = []AtomAuthor{AtomAuthor{Name: "John Doe"}}
+	feed.AddEntry(entry1)
+
+	entry2 := makeTestEntry(t)
+	entry2.Id = "example.com/entry/2"
+	entry2.AddLink("example.com/entry/2", RelAlternate)
+	feed.AddEntry(entry2)
+
+	report := feed.Report()
+	if len(report.Issues) != 1 {
+		t.Errorf("Expected 1 issue, got:\n%s", report)
+	}
+	assertIssue(t, report, "feed/entry[1]", SeverityMust)
+}
+
+func TestValidationReport_DuplicateEntryIds(t *testing.T) {
+	feed := makeValidTestFeed(t)
+	for i := 0; i < 2; i++ {
+		entry := makeTestEntry(t)
+		entry.AddLink("example.com/entry/1", RelAlternate)
+		feed.AddEntry(entry)
+	}
+
+	report := feed.Report()
+	if len(report.Issues) != 1 {
+		t.Errorf("Expected 1 issue, got:\n%s", report)
+	}
+	assertIssue(t, report, "feed/entry[1]", SeverityShould)
+	if !report.Valid() {
+		t.Error("Expected report with only SHOULD issues to be valid")
+	}
+}
+
+func TestValidationReport_EntryRevisions(t *testing.T) {
+	feed := makeValidTestFeed(t)
+	first := makeTestEntry(t)
+	first.AddLink("example.com/entry/1", RelAlternate)
+	feed.AddEntry(first)
+
+	revision := makeTestEntry(t)
+	revision.AddLink("example.com/entry/1", RelAlternate)
+	revision.Updated = AtomDate(time.Time(first.Updated).Add(time.Hour))
+	feed.AddEntry(revision)
+
+	if report := feed.Report(); len(report.Issues) != 0 {
+		t.Errorf("Expected entries sharing an id with different updated dates to be allowed, got:\n%s", report)
+	}
+}
+
+func TestValidationReport_DuplicateAlternateLinks(t *testing.T) {
+	entry := makeTestEntry(t)
+	entry.AddLink("example.com/entry/1", RelAlternate)
+	entry.AddLink("example.com/entry/1.html", RelAlternate)
+	entry.Links = append(entry.Links, AtomLink{
+		Href:     "example.com/entry/1.fr.html",
+		Rel:      RelAlternate,
+		HrefLang: "fr",
+	})
+
+	report := entry.Report()
+	if len(report.Issues) != 1 {
+		t.Errorf("Expected 1 issue, got:\n%s", report)
+	}
+	assertIssue(t, report, "entry/link[1]", SeverityMust)
+}
+
+func TestValidationReport_ContentSrc(t *testing.T) {
+	entry := makeTestEntry(t)
+	entry.Content = &AtomContent{
+		Type: "audio/mpeg",
+		Src:  "example.com/entry/1.mp3",
+	}
+
+	report := entry.Report()
+	if len(report.Issues) != 1 {
+		t.Errorf("Expected 1 issue, got:\n%s", report)
+	}
+	assertIssue(t, report, "entry", SeverityMust)
+
+	entry.SetSummary("An episode", "text")
+	if err := entry.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidationReport_PublishedAfterUpdated(t *testing.T) {
+	entry := makeTestEntry(t)
+	entry.AddLink("example.com/entry/1", RelAlternate)
+	entry.SetPublished(time.Time(entry.Updated).Add(time.Hour))
+
+	report := entry.Report()
+	if len(report.Issues) != 1 {
+		t.Errorf("Expected 1 issue, got:\n%s", report)
+	}
+	assertIssue(t, report, "entry", SeverityShould)
+}
+
+func TestValidateAtomLink_RelTypes(t *testing.T) {
+	valid := []AtomRelType{RelUnknown, RelAlternate, RelPrevArchive, "up", "http://example.com/rel/custom"}
+	for _, rel := range valid {
+		link := AtomLink{Href: "example.com", Rel: rel}
+		if err := link.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	invalid := []AtomRelType{"my/relation", "://missing-scheme", "two words"}
+	for _, rel := range invalid {
+		link := AtomLink{Href: "example.com", Rel: rel}
+		if err := link.Validate(); err == nil {
+			t.Errorf("Expected atom:link with rel=%q to throw error", rel)
+		}
+	}
+}
+
+func TestValidateAtomLink_UnregisteredRel(t *testing.T) {
+	var report ValidationReport
+	AtomLink{Href: "example.com", Rel: "made-up"}.validate(&report, "link")
+	if len(report.Issues) != 1 || report.Issues[0].Severity != SeverityShould {
+		t.Errorf("Expected unregistered rel to be a recommendation, got:\n%s", report)
+	}
+}
+
+func TestValidateAtomLink_EnclosureLength(t *testing.T) {
+	link := AtomLink{Href: "example.com/1.mp3", Rel: RelEnclosure}
+	if err := link.Validate(); err == nil {
+		t.Error("Expected atom:link with rel=\"enclosure\" and no length to throw error")
+	}
+
+	link.Length = 1337
+	if err := link.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,52 @@
+package atom
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+const XHTMLNamespace = "http://www.w3.org/1999/xhtml"
+
+// xhtml constructs are wrapped in a single xhtml div element which is
+// not part of the content itself
+func wrapXHTML(markup string) string {
+	return `<div xmlns="` + XHTMLNamespace + `">` + markup + `</div>`
+}
+
+// IsXHTML reports whether markup can be used as xhtml text or content.
+// Markup which is not well-formed can still be used as html.
+func IsXHTML(markup string) bool {
+	return checkXHTML(markup) == nil
+}
+
+// checkXHTML ensures markup is well-formed XML once wrapped in a div,
+// so it can be written into a document without escaping.
+func checkXHTML(markup string) error {
+	d := xml.NewDecoder(strings.NewReader(wrapXHTML(markup)))
+
+	depth := 0
+	closed := false
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if closed {
+			return errors.New("xhtml markup closes more elements than it opens")
+		}
+
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+			closed = depth == 0
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package cache provides a size bounded cache of values which are loaded
+// once for every caller requesting them and revalidated in the background
+// once they become stale.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Policy determines how long a cached value is used.
+type Policy struct {
+	TTL   time.Duration // how long a value is fresh
+	Stale time.Duration // how long after TTL a value is served while it is revalidated
+}
+
+// Loader loads the value of a key. The context is canceled once no caller
+// is waiting for the value anymore.
+type Loader func(ctx context.Context) (interface{}, error)
+
+// Cache holds up to capacity values, evicting the least recently used value
+// first. It is safe for concurrent use.
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // most recently used first
+	calls    map[string]*call
+	now      func() time.Time
+}
+
+type entry struct {
+	key    string
+	value  interface{}
+	loaded time.Time
+	policy Policy
+}
+
+// call is a load in progress which every caller of the key waits for.
+type call struct {
+	done       chan struct{}
+	value      interface{}
+	err        error
+	waiters    int
+	background bool
+	cancel     context.CancelFunc
+}
+
+// New creates a cache which holds up to capacity values. Caches with a
+// capacity below one store nothing but still share loads between callers.
+func New(capacity int) *Cache {
+	return &Cache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		calls:    map[string]*call{},
+		now:      time.Now,
+	}
+}
+
+// Get returns the value of key. Fresh values are returned as cached. Stale
+// values are returned as cached while a single background load replaces
+// them. Missing and expired values are loaded once, no matter how many
+// callers request them at the same time, and errors are never cached. Get
+// stops waiting when ctx is done.
+func (c *Cache) Get(ctx context.Context, key string, policy Policy, load Loader) (interface{}, error) {
+	c.mu.Lock()
+
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry)
+		age := c.now().Sub(e.loaded)
+		if age < e.policy.TTL+e.policy.Stale {
+			c.order.MoveToFront(element)
+			if age >= e.policy.TTL && c.calls[key] == nil {
+				c.start(key, policy, load, true)
+			}
+			c.mu.Unlock()
+			return e.value, nil
+		}
+		c.remove(element)
+	}
+
+	cl, ok := c.calls[key]
+	if !ok {
+		cl = c.start(key, policy, load, false)
+	}
+	cl.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 && !cl.background {
+			// later callers start over instead of joining a canceled load
+			if c.calls[key] == cl {
+				delete(c.calls, key)
+			}
+			cl.cancel()
+		}
+		c.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// start loads key in the background. The caller must hold c.mu.
+func (c *Cache) start(key string, policy Policy, load Loader, background bool) *call {
+	ctx, cancel := context.WithCancel(context.Background())
+	cl := &call{
+		done:       make(chan struct{}),
+		background: background,
+		cancel:     cancel,
+	}
+	c.calls[key] = cl
+
+	go func() {
+		value, err := load(ctx)
+		cancel()
+
+		c.mu.Lock()
+		if c.calls[key] == cl {
+			delete(c.calls, key)
+		}
+		if err == nil {
+			c.set(key, value, policy)
+		}
+		cl.value, cl.err = value, err
+		c.mu.Unlock()
+
+		close(cl.done)
+	}()
+
+	return cl
+}
+
+// set stores a value, evicting the least recently used values beyond the
+// capacity. The caller must hold c.mu.
+func (c *Cache) set(key string, value interface{}, policy Policy) {
+	e := &entry{
+		key:    key,
+		value:  value,
+		loaded: c.now(),
+		policy: policy,
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = e
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[key] = c.order.PushFront(e)
+	}
+
+	for c.order.Len() > c.capacity && c.order.Len() > 0 {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *Cache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry).key)
+}
+
+// Len returns the number of cached values, including stale values.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var policy = Policy{TTL: time.Minute, Stale: time.Hour}
+
+// clock is a manually advanced time source.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func makeTestCache(capacity int) (*Cache, *clock) {
+	clk := &clock{now: time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)}
+	c := New(capacity)
+	c.now = clk.Now
+	return c, clk
+}
+
+// counter loads the number of times it was called.
+func counter(loads *int32) Loader {
+	return func(ctx context.Context) (interface{}, error) {
+		return int(atomic.AddInt32(loads, 1)), nil
+	}
+}
+
+func get(t *testing.T, c *Cache, key string, load Loader) interface{} {
+	value, err := c.Get(context.Background(), key, policy, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+// waitFor waits for background loads to finish.
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for background load")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCache_Fresh(t *testing.T) {
+	c, clk := makeTestCache(10)
+	var loads int32
+
+	if value := get(t, c, "a", counter(&loads)); value != 1 {
+		t.Errorf("Expected first load, got %v", value)
+	}
+	clk.Advance(30 * time.Second)
+	if value := get(t, c, "a", counter(&loads)); value != 1 {
+		t.Errorf("Expected fresh value to be cached, got %v", value)
+	}
+	if value := get(t, c, "b", counter(&loads)); value != 2 {
+		t.Errorf("Expected keys to be cached separately, got %v", value)
+	}
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	c, clk := makeTestCache(10)
+	var loads int32
+
+	get(t, c, "a", counter(&loads))
+	clk.Advance(2 * time.Minute)
+
+	if value := get(t, c, "a", counter(&loads)); value != 1 {
+		t.Errorf("Expected stale value to be served, got %v", value)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&loads) == 2 })
+	waitFor(t, func() bool {
+		value, _ := c.Get(context.Background(), "a", policy, counter(&loads))
+		return value == 2
+	})
+	if loads := atomic.LoadInt32(&loads); loads != 2 {
+		t.Errorf("Expected one revalidation, got %d loads", loads)
+	}
+}
+
+func TestCache_Expired(t *testing.T) {
+	c, clk := makeTestCache(10)
+	var loads int32
+
+	get(t, c, "a", counter(&loads))
+	clk.Advance(2 * time.Hour)
+
+	if value := get(t, c, "a", counter(&loads)); value != 2 {
+		t.Errorf("Expected expired value to be loaded again, got %v", value)
+	}
+}
+
+func TestCache_Evict(t *testing.T) {
+	c, _ := makeTestCache(2)
+	var loads int32
+
+	get(t, c, "a", counter(&loads))
+	get(t, c, "b", counter(&loads))
+	get(t, c, "a", counter(&loads))
+	get(t, c, "c", counter(&loads))
+
+	if c.Len() != 2 {
+		t.Errorf("Expected cache to hold 2 values, got %d", c.Len())
+	}
+	if value := get(t, c, "a", counter(&loads)); value != 1 {
+		t.Errorf("Expected recently used value to be kept, got %v", value)
+	}
+	if value := get(t, c, "b", counter(&loads)); value != 4 {
+		t.Errorf("Expected least recently used value to be evicted, got %v", value)
+	}
+}
+
+func TestCache_Coalesce(t *testing.T) {
+	c, _ := makeTestCache(10)
+	var loads int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "feed", nil
+	}
+
+	var wg sync.WaitGroup
+	values := make([]interface{}, 20)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], _ = c.Get(context.Background(), "a", policy, load)
+		}(i)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&loads) > 0 })
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Errorf("Expected concurrent requests to share one load, got %d loads", loads)
+	}
+	for _, value := range values {
+		if value != "feed" {
+			t.Errorf("Expected every caller to get the loaded value, got %v", value)
+		}
+	}
+}
+
+func TestCache_ErrorsAreNotCached(t *testing.T) {
+	c, _ := makeTestCache(10)
+	var loads int32
+
+	_, err := c.Get(context.Background(), "a", policy, func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("upstream unavailable")
+	})
+	if err == nil {
+		t.Error("Expected load error to be returned")
+	}
+	if value := get(t, c, "a", counter(&loads)); value != 1 {
+		t.Errorf("Expected failed load to be retried, got %v", value)
+	}
+}
+
+func TestCache_Cancel(t *testing.T) {
+	c, _ := makeTestCache(10)
+	canceled := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Get(ctx, "a", policy, load); err != context.Canceled {
+		t.Errorf("Expected canceled request to return context.Canceled, got %v", err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("Expected load to be canceled once no caller is waiting")
+	}
+}
@@ -0,0 +1,173 @@
+// Package date parses the date formats found in feeds and the services
+// feeds are generated from. Written dates are only understood in English.
+package date
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// layouts in order of preference, without a leading day of the week
+var layouts = []string{
+	// RFC 3339 and ISO 8601
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+
+	// RFC 822, RFC 1123 and RFC 850 with four or two digit years
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 06 15:04 MST",
+	"2-Jan-06 15:04:05 MST",
+	"2-Jan-2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05",
+
+	// ANSI C and Unix date
+	"Jan _2 15:04:05 2006",
+	"Jan _2 15:04:05 MST 2006",
+	"Jan 02 15:04:05 -0700 2006",
+
+	// written dates
+	"2 January 2006 15:04:05 MST",
+	"2 January 2006 15:04",
+	"2 January 2006",
+	"2 Jan 2006",
+	"January 2, 2006 15:04:05 MST",
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006",
+	"Jan 2, 2006 15:04:05 MST",
+	"Jan 2, 2006 3:04 PM",
+	"Jan 2, 2006",
+}
+
+// offsets of the time zones defined in RFC 822 and of other common zone
+// names. Names missing here are rejected since their offset is unknown, as
+// are ambiguous names such as IST.
+var zoneOffsets = map[string]int{
+	// RFC 822
+	"UTC": 0,
+	"GMT": 0,
+	"EST": -5 * 60 * 60,
+	"EDT": -4 * 60 * 60,
+	"CST": -6 * 60 * 60,
+	"CDT": -5 * 60 * 60,
+	"MST": -7 * 60 * 60,
+	"MDT": -6 * 60 * 60,
+	"PST": -8 * 60 * 60,
+	"PDT": -7 * 60 * 60,
+
+	// North America
+	"AKST": -9 * 60 * 60,
+	"AKDT": -8 * 60 * 60,
+	"HST":  -10 * 60 * 60,
+
+	// Europe
+	"WET":  0,
+	"WEST": 1 * 60 * 60,
+	"BST":  1 * 60 * 60,
+	"CET":  1 * 60 * 60,
+	"CEST": 2 * 60 * 60,
+	"EET":  2 * 60 * 60,
+	"EEST": 3 * 60 * 60,
+	"MSK":  3 * 60 * 60,
+
+	// Asia and Oceania
+	"JST":  9 * 60 * 60,
+	"KST":  9 * 60 * 60,
+	"AEST": 10 * 60 * 60,
+	"AEDT": 11 * 60 * 60,
+	"NZST": 12 * 60 * 60,
+	"NZDT": 13 * 60 * 60,
+}
+
+var (
+	weekdayMatcher    = regexp.MustCompile(`^(?i)(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s+`)
+	commentMatcher    = regexp.MustCompile(`\s*\([^)]*\)$`)
+	universalMatcher  = regexp.MustCompile(` (UT|Z)$`)
+	ordinalMatcher    = regexp.MustCompile(`\b(\d{1,2})(st|nd|rd|th)\b`)
+	whitespaceMatcher = regexp.MustCompile(`\s+`)
+	timestampMatcher  = regexp.MustCompile(`^\d{9,13}$`)
+)
+
+// Parse parses a date in any of the supported formats. Dates without a
+// time zone are in UTC.
+func Parse(text string) (time.Time, error) {
+	return ParseInLocation(text, time.UTC)
+}
+
+// ParseInLocation parses a date like Parse, but dates without a time zone
+// are in the given location.
+func ParseInLocation(text string, loc *time.Location) (time.Time, error) {
+	normalized := normalize(text)
+	if len(normalized) == 0 {
+		return time.Time{}, errors.New("date cannot be empty")
+	}
+
+	// unix timestamps in seconds or milliseconds
+	if timestampMatcher.MatchString(normalized) {
+		timestamp, _ := strconv.ParseInt(normalized, 10, 64)
+		if len(normalized) > 10 {
+			return time.UnixMilli(timestamp).UTC(), nil
+		}
+		return time.Unix(timestamp, 0).UTC(), nil
+	}
+
+	for _, layout := range layouts {
+		if datetime, err := time.ParseInLocation(layout, normalized, loc); err == nil {
+			return fixZone(datetime, loc)
+		}
+	}
+
+	return time.Time{}, errors.New("unable to parse date '" + text + "'")
+}
+
+func normalize(text string) string {
+	text = strings.TrimSpace(whitespaceMatcher.ReplaceAllString(text, " "))
+	// the day of the week is redundant and often misspelled
+	text = weekdayMatcher.ReplaceAllString(text, "")
+	// RFC 822 allows comments such as "+0000 (UTC)"
+	text = commentMatcher.ReplaceAllString(text, "")
+	// RFC 822 names universal time "UT" or "Z", which are too short to parse
+	text = universalMatcher.ReplaceAllString(text, " UTC")
+	// written dates may use ordinal days such as "July 4th"
+	return ordinalMatcher.ReplaceAllString(text, "$1")
+}
+
+// fixZone applies the offsets of zone names which were parsed without a
+// known offset. Zone names unknown to both zoneOffsets and loc are parsed
+// as UTC, so they are rejected.
+func fixZone(datetime time.Time, loc *time.Location) (time.Time, error) {
+	name, offset := datetime.Zone()
+	expected, ok := zoneOffsets[name]
+	if !ok {
+		// numeric offsets are parsed without a name
+		if len(name) > 0 && datetime.Location() != loc {
+			return time.Time{}, errors.New("unknown time zone '" + name + "'")
+		}
+		return datetime, nil
+	}
+	if offset == expected {
+		return datetime, nil
+	}
+
+	year, month, day := datetime.Date()
+	hour, min, sec := datetime.Clock()
+	return time.Date(year, month, day, hour, min, sec, datetime.Nanosecond(), time.FixedZone(name, expected)), nil
+}
@@ -0,0 +1,131 @@
+package date
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	utc := time.Date(2022, 7, 4, 12, 34, 56, 0, time.UTC)
+	day := time.Date(2022, 7, 4, 0, 0, 0, 0, time.UTC)
+	minute := time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)
+
+	tests := []struct {
+		text string
+		ref  time.Time
+	}{
+		// RFC 3339 and ISO 8601
+		{"2022-07-04T12:34:56Z", utc},
+		{"2022-07-04T12:34:56.789Z", utc.Add(789 * time.Millisecond)},
+		{"2022-07-04T05:34:56-07:00", utc},
+		{"2022-07-04T05:34:56-0700", utc},
+		{"2022-07-04T12:34:56", utc},
+		{"2022-07-04 12:34:56", utc},
+		{"2022-07-04", day},
+
+		// RFC 822 and RFC 1123
+		{"Mon, 04 Jul 2022 12:34:56 GMT", utc},
+		{"Mon, 04 Jul 2022 12:34:56 +0000", utc},
+		{"Mon, 04 Jul 2022 05:34:56 -0700", utc},
+		{"Mon, 04 Jul 2022 05:34:56 PDT", utc},
+		{"Mon, 04 Jul 2022 12:34:56 UT", utc},
+		{"Mon, 04 Jul 2022 12:34:56 Z", utc},
+		{"Mon, 04 Jul 2022 14:34:56 CEST", utc},
+		{"Mon, 04 Jan 2022 13:34:56 CET", utc.AddDate(0, -6, 0)},
+		{"Mon, 04 Jul 2022 13:34:56 BST", utc},
+		{"Mon, 4 Jul 2022 12:34:56 GMT", utc},
+		{"Monday, 04 Jul 2022 12:34:56 GMT", utc},
+		{"Tue, 04 Jul 2022 12:34:56 GMT", utc},
+		{"04 Jul 2022 12:34:56 +0000", utc},
+		{"Mon, 04 Jul 22 12:34:56 GMT", utc},
+		{"Mon, 04 Jul 22 12:34 +0000", minute},
+		{"Mon, 04 Jul 2022 12:34:56 +0000 (UTC)", utc},
+		{"Monday, 04-Jul-22 12:34:56 GMT", utc},
+		{"  Mon,  04 Jul 2022\n12:34:56 GMT ", utc},
+
+		// ANSI C
+		{"Mon Jul  4 12:34:56 2022", utc},
+		{"Mon Jul  4 12:34:56 UTC 2022", utc},
+
+		// unix timestamps
+		{"1656938096", utc},
+		{"1656938096000", utc},
+
+		// written dates
+		{"July 4, 2022", day},
+		{"Jul 4, 2022", day},
+		{"July 4th, 2022", day},
+		{"4 July 2022", day},
+		{"4 Jul 2022", day},
+		{"July 4, 2022 12:34 PM", minute},
+		{"Monday, July 4, 2022", day},
+	}
+
+	for _, test := range tests {
+		datetime, err := Parse(test.text)
+		if err != nil {
+			t.Errorf("Expected '%s' to parse, got %v", test.text, err)
+			continue
+		}
+		if !datetime.Equal(test.ref) {
+			t.Errorf("Expected '%s' to parse as %s, got %s", test.text, test.ref, datetime)
+		}
+	}
+
+	if datetime, err := Parse("Mon, 04 Jul 2022 05:34:56 PDT"); err == nil {
+		if _, offset := datetime.Zone(); offset != -7*60*60 {
+			t.Errorf("Expected PDT to have offset -07:00, got %d", offset)
+		}
+	}
+}
+
+func TestParse_ZoneNames(t *testing.T) {
+	datetime, err := Parse("Mon, 02 Jan 2006 15:04:05 CET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := time.Date(2006, 1, 2, 14, 4, 5, 0, time.UTC); !datetime.Equal(ref) {
+		t.Errorf("Expected CET to have offset +01:00, got %s", datetime)
+	}
+
+	// zone names of the location are known without the table
+	loc, err := time.LoadLocation("Australia/Adelaide")
+	if err != nil {
+		t.Skip(err)
+	}
+	datetime, err = ParseInLocation("Mon, 04 Jul 2022 12:34:56 ACST", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := time.Date(2022, 7, 4, 3, 4, 56, 0, time.UTC); !datetime.Equal(ref) {
+		t.Errorf("Expected ACST to have the offset of the location, got %s", datetime)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, text := range []string{"", "  ", "yesterday", "2022-13-45", "12345", "Mon, 32 Jul 2022 12:34:56 GMT", "Mon, 04 Jul 2022 12:34:56 IST", "Mon, 04 Jul 2022 12:34:56 XYZ"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Expected '%s' to throw error", text)
+		}
+	}
+}
+
+func TestParseInLocation(t *testing.T) {
+	loc := time.FixedZone("AEST", 10*60*60)
+
+	datetime, err := ParseInLocation("2022-07-04 12:34:56", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := time.Date(2022, 7, 4, 2, 34, 56, 0, time.UTC); !datetime.Equal(ref) {
+		t.Errorf("Expected date without zone to be in location, got %s", datetime)
+	}
+
+	datetime, err = ParseInLocation("2022-07-04T12:34:56Z", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := time.Date(2022, 7, 4, 12, 34, 56, 0, time.UTC); !datetime.Equal(ref) {
+		t.Errorf("Expected date with zone to ignore location, got %s", datetime)
+	}
+}
@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/xml"
-	"net/http"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bossley9/feedme/pkg/api"
 	"github.com/bossley9/feedme/pkg/atom"
+	"github.com/bossley9/feedme/pkg/cache"
 )
 
 type acastResponse struct {
@@ -69,7 +74,7 @@ type acastResponse struct {
 		Item       []struct {
 			Text      string `xml:",chardata"`
 			Title     string `xml:"title"`
-			PubDate   string `xml:"pubDate"` // adheres to RFC 1123
+			PubDate   string `xml:"pubDate"` // usually RFC 1123
 			Duration  string `xml:"duration"`
 			Enclosure struct {
 				Text   string `xml:",chardata"`
@@ -108,40 +113,98 @@ type acastResponse struct {
 	} `xml:"channel"`
 }
 
-func HandleAcast(w http.ResponseWriter, r *http.Request) {
-	showID := r.FormValue("show")
-	if len(showID) == 0 {
-		HandleUsage(w, r, "/acast?show={SHOW_ID}")
-		return
+// parseDuration parses itunes:duration values, which are either seconds
+// or formatted as "HH:MM:SS" or "MM:SS".
+func parseDuration(text string) (time.Duration, error) {
+	var duration time.Duration
+	if len(text) == 0 {
+		return duration, errors.New("duration cannot be empty")
 	}
 
-	url := "https://feeds.acast.com/public/shows/" + showID
-	raw, err := api.FetchGet(url)
+	for _, part := range strings.Split(text, ":") {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, err
+		}
+		duration = duration*60 + time.Duration(value)*time.Second
+	}
+	return duration, nil
+}
+
+func isExplicit(text string) bool {
+	switch strings.ToLower(text) {
+	case "true", "yes", "explicit":
+		return true
+	}
+	return false
+}
+
+type acastSource struct{}
+
+func (acastSource) Name() string {
+	return "acast"
+}
+
+func (acastSource) Description() string {
+	return "Acast podcasts"
+}
+
+func (acastSource) Params() []Param {
+	return []Param{
+		{Name: "show", Placeholder: "SHOW_ID", Description: "id or name of the show", Required: true},
+	}
+}
+
+// podcasts are published at most a few times a week, so feeds are cached
+// for an hour
+func (acastSource) CachePolicy() cache.Policy {
+	return cache.Policy{TTL: time.Hour, Stale: 6 * time.Hour}
+}
+
+func (acastSource) Fetch(ctx context.Context, params Params) (*atom.AtomFeed, error) {
+	url := "https://feeds.acast.com/public/shows/" + params.Get("show")
+	raw, err := api.FetchGetContext(ctx, url)
 	if err != nil {
-		HandleBadRequest(w, r, err)
-		return
+		return nil, badRequest(err)
 	}
 
 	var response acastResponse
 	if err := xml.Unmarshal(raw, &response); err != nil {
-		HandleBadRequest(w, r, err)
-		return
+		return nil, badRequest(err)
 	}
 
 	data := response.Channel
 
-	// Acast provides no update time so we use the current time
-	feed, err := atom.CreateFeed(url, data.Title, time.Now())
-	if err != nil {
-		HandleBadRequest(w, r, err)
-		return
+	feedID := url
+	if len(data.ShowId) > 0 {
+		feedID = getStableID("acast:shows:" + data.ShowId)
 	}
 
-	feed.AddAuthor(data.Owner.Name, "", data.Owner.Email)
-	feed.AddLink(url, atom.RelSelf)
-	feed.SetSubtitle(data.Subtitle, "text")
-	feed.SetCopyright(data.Copyright, "text")
-	feed.SetLogo(data.Image.URL)
+	// Acast provides no update time so we use the current time
+	builder := atom.NewFeedBuilder(feedID, data.Title, getGeneratedTime()).
+		Link(url, atom.RelSelf)
+
+	if len(data.Owner.Name) > 0 {
+		builder.Author(data.Owner.Name, "", data.Owner.Email)
+	}
+	if len(data.Subtitle) > 0 {
+		builder.Subtitle(data.Subtitle, "text")
+	}
+	if len(data.Copyright) > 0 {
+		builder.Copyright(data.Copyright, "text")
+	}
+	if len(data.Image.URL) > 0 {
+		builder.Logo(data.Image.URL)
+	}
+	if len(data.Language) > 0 {
+		builder.Language(data.Language)
+	}
+	if len(data.Image.Href) > 0 {
+		builder.Extension(atom.ITunesImage(data.Image.Href))
+	}
+	if len(data.Explicit) > 0 {
+		builder.Extension(atom.ITunesExplicit(isExplicit(data.Explicit)))
+	}
 
 	for _, cat := range data.Category {
 		// Categories can be (single-level) nested within categories
@@ -149,23 +212,82 @@ func HandleAcast(w http.ResponseWriter, r *http.Request) {
 		if len(cat.Category.AttrText) > 0 {
 			category = cat.Category.AttrText
 		}
-		feed.AddCategory(category, "", "")
+		if len(category) > 0 {
+			builder.Category(category, "", "")
+		}
 	}
 
+	// items are listed newest first, so an item with an unreadable date
+	// takes the date of the item before it to keep its position
+	published := time.Unix(0, 0).UTC()
 	for _, item := range data.Item {
-		published := getDatetime(item.PubDate, time.RFC1123)
+		published = getDatetime(item.PubDate, published)
 
-		entry, err := atom.CreateFeedEntry(item.EpisodeId, item.Title, published)
-		if err != nil {
+		entryID := item.Guid.Text
+		if len(item.EpisodeId) > 0 {
+			entryID = "acast:episodes:" + item.EpisodeId
+		}
+		if len(entryID) == 0 {
 			continue
 		}
 
-		entry.AddLink(item.Enclosure.URL, atom.RelAlternate)
-		entry.SetPublished(published)
-		entry.SetSummary(item.Description, "html")
+		alternate := item.Link
+		if len(alternate) == 0 {
+			alternate = item.Enclosure.URL
+		}
 
-		feed.AddEntry(entry)
+		entryBuilder := atom.NewEntryBuilder(getStableID(entryID), item.Title, published).
+			Published(published)
+
+		if len(alternate) > 0 {
+			entryBuilder.Link(alternate, atom.RelAlternate)
+		}
+		if len(item.Description) > 0 {
+			entryBuilder.Summary(item.Description, "html")
+		}
+		if len(item.Enclosure.URL) > 0 {
+			length, _ := strconv.ParseUint(item.Enclosure.Length, 10, 0)
+			entryBuilder.LinkWithOptions(item.Enclosure.URL, atom.RelEnclosure, atom.LinkOptions{
+				Type:   item.Enclosure.Type,
+				Length: uint(length),
+			})
+		}
+
+		if duration, err := parseDuration(item.Duration); err == nil {
+			entryBuilder.Extension(atom.ITunesDuration(duration))
+		}
+		if season, err := strconv.Atoi(item.Season); err == nil {
+			entryBuilder.Extension(atom.ITunesSeason(season))
+		}
+		if episode, err := strconv.Atoi(item.Episode); err == nil {
+			entryBuilder.Extension(atom.ITunesEpisode(episode))
+		}
+		if len(item.EpisodeType) > 0 {
+			entryBuilder.Extension(atom.ITunesEpisodeType(item.EpisodeType))
+		}
+		if len(item.Explicit) > 0 {
+			entryBuilder.Extension(atom.ITunesExplicit(isExplicit(item.Explicit)))
+		}
+		if len(item.Image.Href) > 0 {
+			entryBuilder.Extension(atom.ITunesImage(item.Image.Href))
+		}
+
+		// episodes with invalid data are skipped
+		entry, err := entryBuilder.Build()
+		if err != nil {
+			log.Printf("skipping acast episode %s: %v", entryID, err)
+			continue
+		}
+		builder.Entry(entry)
+	}
+
+	feed, err := buildFeed(params, builder)
+	if err != nil {
+		return nil, err
 	}
+	// the newest episode dates the feed, so unchanged feeds keep their
+	// validators across refreshes
+	feed.RefreshUpdated()
 
-	HandleSuccess(w, r, feed)
+	return feed, nil
 }
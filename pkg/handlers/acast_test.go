@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestAcastSource_EmptyOptionalFields(t *testing.T) {
+	useFixture(t, "testdata/acast_empty.xml")
+
+	params := Params{Values: url.Values{"show": {"empty-show"}}}
+	feed, err := acastSource{}.Fetch(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Subtitle != nil || feed.Rights != nil || len(feed.Categories) != 0 {
+		t.Error("Expected empty subtitle, rights and categories to be left out")
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(feed.Entries))
+	}
+	for _, entry := range feed.Entries {
+		if entry.Summary != nil {
+			t.Errorf("Expected empty description of %s to be left out", entry.Id)
+		}
+	}
+	if report := feed.Report(); !report.Valid() {
+		t.Errorf("Expected valid feed, got:\n%s", report)
+	}
+}
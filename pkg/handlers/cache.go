@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/bossley9/feedme/pkg/atom"
+	"github.com/bossley9/feedme/pkg/cache"
+)
+
+// CachedSource is a feed source which sets how long its feeds are cached.
+// Feeds of other sources are cached with defaultCachePolicy.
+type CachedSource interface {
+	FeedSource
+	CachePolicy() cache.Policy
+}
+
+var defaultCachePolicy = cache.Policy{TTL: 15 * time.Minute, Stale: time.Hour}
+
+var feedCache = cache.New(256)
+
+// fetchTimeout bounds how long a source may take to generate a feed, so
+// slow upstream services are reported before the server's write timeout.
+const fetchTimeout = 10 * time.Second
+
+// SetCacheSize sets how many feeds are cached. Requests for the same feed
+// share a single fetch even when the size is 0.
+func SetCacheSize(size int) {
+	feedCache = cache.New(size)
+}
+
+func getCachePolicy(source FeedSource) cache.Policy {
+	if cached, ok := source.(CachedSource); ok {
+		return cached.CachePolicy()
+	}
+	return defaultCachePolicy
+}
+
+// cachedFeed is a generated feed which is never modified once cached, so it
+// can be served to concurrent requests.
+type cachedFeed struct {
+	feed    *atom.AtomFeed
+	repairs []string
+	expires time.Time // when the feed stops being fresh, zero if not cached
+}
+
+// fetchFeed returns the cached feed of the request, fetching it from the
+// source when it is missing or stale. The request url is also the cache key,
+// so it should only contain the parameters of the source. Fetches are
+// canceled once every request waiting for them is done.
+func fetchFeed(ctx context.Context, source FeedSource, requestURL *url.URL) (*cachedFeed, error) {
+	key := requestURL.String()
+
+	policy := getCachePolicy(source)
+
+	value, err := feedCache.Get(ctx, key, policy, func(ctx context.Context) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+		defer cancel()
+
+		var repairs []string
+		params := Params{Values: requestURL.Query(), URL: requestURL, repairs: &repairs}
+		feed, err := source.Fetch(ctx, params)
+		if err != nil {
+			log.Printf("unable to fetch feed %s: %v", key, err)
+			return nil, err
+		}
+		// repairs may add dates, so dates are normalized afterwards
+		repairs = repairFeed(key, feed, repairs)
+		if dateLocation != nil {
+			feed.NormalizeDates(dateLocation)
+		}
+		return &cachedFeed{
+			feed:    feed,
+			repairs: repairs,
+			expires: time.Now().Add(policy.TTL),
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*cachedFeed), nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+// datedSource generates a feed updated at a fixed date with an offset.
+type datedSource struct{}
+
+func (datedSource) Name() string        { return "dated" }
+func (datedSource) Description() string { return "" }
+func (datedSource) Params() []Param     { return nil }
+
+func (datedSource) Fetch(ctx context.Context, params Params) (*atom.AtomFeed, error) {
+	return makeTestBuilder(testDate.In(time.FixedZone("", 2*60*60))).BuildUnvalidated()
+}
+
+func TestFetchFeed_DateLocation(t *testing.T) {
+	SetCacheSize(0)
+	SetDateLocation(time.UTC)
+	t.Cleanup(func() {
+		SetCacheSize(256)
+		SetDateLocation(nil)
+	})
+
+	cached, err := fetchFeed(context.Background(), datedSource{}, &url.URL{Path: "/dated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated := cached.feed.Updated.String(); updated != "2022-07-04T12:34:00Z" {
+		t.Errorf("Expected date to be written in UTC, got %s", updated)
+	}
+}
+
+func TestFetchFeed_KeepsOffsets(t *testing.T) {
+	SetCacheSize(0)
+	t.Cleanup(func() {
+		SetCacheSize(256)
+	})
+
+	cached, err := fetchFeed(context.Background(), datedSource{}, &url.URL{Path: "/dated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated := cached.feed.Updated.String(); updated != "2022-07-04T14:34:00+02:00" {
+		t.Errorf("Expected date to keep its offset, got %s", updated)
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getETag returns a strong entity tag of the response body, which differs
+// between formats of the same feed.
+func getETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// setValidators sets the headers which clients use to revalidate the
+// response. Last-Modified is only set when the feed has an update time.
+func setValidators(w http.ResponseWriter, etag string, lastModified time.Time, expires time.Time) {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if expires.IsZero() {
+		w.Header().Set("Cache-Control", "no-cache")
+		return
+	}
+
+	maxAge := int(time.Until(expires).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+}
+
+// isNotModified reports whether the client already has the response, as
+// described in RFC 7232. If-Modified-Since is ignored when If-None-Match is
+// present.
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); len(ifNoneMatch) > 0 {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			// If-None-Match uses the weak comparison
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// Last-Modified has a precision of one second
+	return !lastModified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+type diffEntry struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url,omitempty"`
+}
+
+type diffResponse struct {
+	Added    []diffEntry `json:"added"`
+	Removed  []diffEntry `json:"removed"`
+	Modified []diffEntry `json:"modified"`
+	Metadata []string    `json:"metadata"`
+}
+
+func createDiffEntries(entries []atom.AtomEntry) []diffEntry {
+	diffEntries := []diffEntry{}
+	for _, entry := range entries {
+		item := diffEntry{
+			ID:    string(entry.Id),
+			Title: atom.AtomTextConstruct(entry.Title).PlainText(),
+		}
+		for _, link := range entry.Links {
+			if link.Rel == atom.RelAlternate || link.Rel == atom.RelUnknown {
+				item.URL = string(link.Href)
+				break
+			}
+		}
+		diffEntries = append(diffEntries, item)
+	}
+	return diffEntries
+}
+
+// maxSnapshotSize bounds the size of the previous snapshot in bytes.
+const maxSnapshotSize = 10 << 20
+
+// encodeDiff compares the feed with the previous snapshot of the feed sent
+// as the request body.
+func encodeDiff(w io.Writer, r *http.Request, feed *atom.AtomFeed) error {
+	if r.Method != http.MethodPost {
+		return errors.New("format 'diff' requires the previous feed as the body of a POST request.")
+	}
+
+	previous, err := atom.Parse(r.Body)
+	if err != nil {
+		return err
+	}
+
+	diff := atom.Diff(previous, feed)
+	res := diffResponse{
+		Added:    createDiffEntries(diff.Added),
+		Removed:  createDiffEntries(diff.Removed),
+		Modified: createDiffEntries(diff.Modified),
+		Metadata: diff.Metadata,
+	}
+	if res.Metadata == nil {
+		res.Metadata = []string{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(res)
+}
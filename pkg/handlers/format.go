@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bossley9/feedme/pkg/atom"
+	"github.com/bossley9/feedme/pkg/jsonfeed"
+	"github.com/bossley9/feedme/pkg/rss"
+)
+
+type feedFormat struct {
+	name      string
+	mediaType string
+	filename  string
+}
+
+var (
+	atomFormat = feedFormat{"atom", "application/atom+xml", "feed.xml"}
+	rssFormat  = feedFormat{"rss", "application/rss+xml", "feed.xml"}
+	jsonFormat = feedFormat{"json", "application/feed+json", "feed.json"}
+)
+
+// in order of preference
+var feedFormats = []feedFormat{atomFormat, rssFormat, jsonFormat}
+
+// diffFormat compares the feed with a previous Atom snapshot sent as the
+// request body. It is never chosen from the Accept header.
+var diffFormat = feedFormat{"diff", "application/json", "diff.json"}
+
+// getFormat returns the output format requested with the format parameter,
+// falling back to the Accept header and then to Atom.
+func getFormat(r *http.Request) (feedFormat, error) {
+	name := r.URL.Query().Get("format")
+	if len(name) == 0 {
+		return getAcceptedFormat(r.Header.Get("Accept")), nil
+	}
+
+	if name == diffFormat.name {
+		return diffFormat, nil
+	}
+	for _, format := range feedFormats {
+		if format.name == name {
+			return format, nil
+		}
+	}
+	return feedFormat{}, errors.New("format '" + name + "' not found.")
+}
+
+func getAcceptedFormat(accept string) feedFormat {
+	best := atomFormat
+	bestQuality := 0.0
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			keyValue := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(keyValue) == 2 && keyValue[0] == "q" {
+				if q, err := strconv.ParseFloat(keyValue[1], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		for _, format := range feedFormats {
+			if format.mediaType == mediaType && quality > bestQuality {
+				best = format
+				bestQuality = quality
+			}
+		}
+	}
+
+	return best
+}
+
+func encodeFeed(w io.Writer, feed *atom.AtomFeed, format feedFormat) error {
+	switch format {
+	case rssFormat:
+		return rss.FromAtom(feed).Encode(w)
+	case jsonFormat:
+		return jsonfeed.FromAtom(feed).Encode(w)
+	default:
+		return feed.Encode(w)
+	}
+}
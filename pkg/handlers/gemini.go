@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"net/http"
+	"context"
 	"net/url"
 	"regexp"
 	"strings"
@@ -10,12 +10,13 @@ import (
 
 	"github.com/bossley9/feedme/pkg/api"
 	"github.com/bossley9/feedme/pkg/atom"
+	"github.com/bossley9/feedme/pkg/cache"
+	"github.com/bossley9/feedme/pkg/date"
 
 	"github.com/bossley9/gem"
 )
 
 const geminiProtocol = "gemini://"
-const iSO8601 = "2006-01-02"
 
 const (
 	titleState = iota
@@ -23,9 +24,9 @@ const (
 	entryState
 )
 
-func parseGemlogEntry(feed *atom.AtomFeed, feedUrl string, line string, wg *sync.WaitGroup) {
-	defer wg.Done()
-
+// parseGemlogEntry creates an entry from a gemlog link line, or returns nil
+// when the line does not form a valid entry.
+func parseGemlogEntry(ctx context.Context, feedUrl string, line string) *atom.AtomEntry {
 	trimmedLine := strings.TrimSpace(strings.TrimPrefix(line, "=>"))
 	lineSections := strings.Split(trimmedLine, " ")
 
@@ -40,9 +41,9 @@ func parseGemlogEntry(feed *atom.AtomFeed, feedUrl string, line string, wg *sync
 	}
 
 	updatedText := lineSections[1]
-	updated, err := time.Parse(iSO8601, updatedText)
+	updated, err := date.Parse(updatedText)
 	if err != nil {
-		return
+		return nil
 	}
 
 	title := strings.Join(lineSections[2:], " ")
@@ -50,58 +51,80 @@ func parseGemlogEntry(feed *atom.AtomFeed, feedUrl string, line string, wg *sync
 	// gemini.circumlunar.space/docs/companion/subscription.gmi
 	title = strings.TrimPrefix(title, "- ")
 
-	entry, err := atom.CreateFeedEntry(entryUrl, title, updated)
-	if err != nil {
-		return
+	// gemlog entries have no identifier besides their url, so the id is
+	// derived from the url alone and survives edits to the listed date
+	id := entryUrl
+	if parsedUrl, err := url.Parse(entryUrl); err == nil && len(parsedUrl.Host) > 0 {
+		id = getStableID("gemini:" + parsedUrl.Host + parsedUrl.Path)
+	}
+
+	builder := atom.NewEntryBuilder(id, title, updated).
+		Link(entryUrl, atom.RelAlternate)
+
+	// entries which cannot be fetched only link to the gemtext
+	res, err := api.FetchGeminiContext(ctx, entryUrl)
+	if err == nil {
+		// not all gemtext converts to well-formed xhtml
+		content := gem.ToHTML(string(res))
+		contentType := "html"
+		if atom.IsXHTML(content) {
+			contentType = "xhtml"
+		}
+
+		// relative links within the gemtext resolve against the entry itself
+		builder.Base(entryUrl).Content(content, contentType)
 	}
 
-	res, err := api.FetchGemini(entryUrl)
+	entry, err := builder.Build()
 	if err != nil {
-		// add alt link entry if unable to fetch
-		entry.AddLink(entryUrl, atom.RelAlternate)
-		feed.AddEntry(entry)
-		return
+		return nil
 	}
+	return entry
+}
 
-	entry.AddLink(entryUrl, atom.RelAlternate)
+type geminiSource struct{}
 
-	content := gem.ToHTML(string(res))
-	entry.SetContent(content, "html")
+func (geminiSource) Name() string {
+	return "gemini"
+}
 
-	feed.AddEntry(entry)
+func (geminiSource) Description() string {
+	return "gemlogs served over the Gemini protocol"
 }
 
-func HandleGemini(w http.ResponseWriter, r *http.Request) {
-	encodedUrl := r.FormValue("url")
-	if len(encodedUrl) == 0 {
-		HandleUsage(w, r, "/gemini?url={ENCODED_URL_WITH_NO_PROTOCOL}")
-		return
+func (geminiSource) Params() []Param {
+	return []Param{
+		{Name: "url", Placeholder: "ENCODED_URL_WITH_NO_PROTOCOL", Description: "url of the gemlog without gemini://", Required: true},
 	}
+}
 
-	decodedUrl, err := url.QueryUnescape(encodedUrl)
-	if err != nil {
-		HandleBadRequest(w, r, err)
-		return
-	}
-	formattedUrl := geminiProtocol + decodedUrl
+// every post of a gemlog is fetched on each refresh, so feeds are cached
+// for an hour
+func (geminiSource) CachePolicy() cache.Policy {
+	return cache.Policy{TTL: time.Hour, Stale: 6 * time.Hour}
+}
 
-	res, err := api.FetchGemini(formattedUrl)
+func (geminiSource) Fetch(ctx context.Context, params Params) (*atom.AtomFeed, error) {
+	decodedUrl, err := url.QueryUnescape(params.Get("url"))
 	if err != nil {
-		HandleBadRequest(w, r, err)
-		return
+		return nil, badRequest(err)
 	}
+	formattedUrl := geminiProtocol + decodedUrl
 
-	feed, err := atom.CreateFeed(formattedUrl, "gemlog", time.Now())
+	res, err := api.FetchGeminiContext(ctx, formattedUrl)
 	if err != nil {
-		HandleBadRequest(w, r, err)
-		return
+		return nil, badRequest(err)
 	}
 
-	feed.AddLink(formattedUrl, atom.RelSelf)
+	// relative entry links resolve under the gemlog path, so the base is
+	// treated as a directory
+	builder := atom.NewFeedBuilder(formattedUrl, "gemlog", getGeneratedTime()).
+		Link(formattedUrl, atom.RelSelf).
+		Base(strings.TrimSuffix(formattedUrl, "/") + "/")
 
 	entryMatcher := regexp.MustCompilePOSIX("^=> .* ....-..-..")
 
-	var wg sync.WaitGroup
+	var entryLines []string
 	state := titleState
 	for _, line := range strings.Split(string(res), "\n") {
 		if len(strings.TrimSpace(line)) == 0 {
@@ -110,34 +133,46 @@ func HandleGemini(w http.ResponseWriter, r *http.Request) {
 
 		if state == titleState && strings.HasPrefix(line, "#") {
 			title := strings.TrimSpace(strings.TrimPrefix(line, "#"))
-			feed.SetTitle(title, "text")
+			builder.Title(title, "text")
 			state = subtitleState
 		} else if state == subtitleState {
 			if strings.HasPrefix(line, "##") {
 				subtitle := strings.TrimSpace(strings.TrimPrefix(line, "##"))
-				feed.SetSubtitle(subtitle, "text")
+				builder.Subtitle(subtitle, "text")
 			}
 			state = entryState
 		}
 
 		if state == entryState && entryMatcher.MatchString(line) {
-			wg.Add(1)
-			go parseGemlogEntry(feed, formattedUrl, line, &wg)
+			entryLines = append(entryLines, line)
 		}
 	}
 
+	// entries are fetched concurrently, each into its own slot so the
+	// gemlog order is kept
+	entries := make([]*atom.AtomEntry, len(entryLines))
+	var wg sync.WaitGroup
+	for i, line := range entryLines {
+		wg.Add(1)
+		go func(i int, line string) {
+			defer wg.Done()
+			entries[i] = parseGemlogEntry(ctx, formattedUrl, line)
+		}(i, line)
+	}
 	wg.Wait()
 
-	updated := time.UnixMicro(0) // get unix epoch timestamp 0
-	for _, entry := range feed.Entries {
-		date := time.Time(entry.Updated)
-		if date.Sub(updated) > 0 {
-			updated = date
+	for _, entry := range entries {
+		if entry != nil {
+			builder.Entry(entry)
 		}
 	}
-	if !updated.IsZero() {
-		feed.SetUpdated(updated)
+
+	feed, err := buildFeed(params, builder)
+	if err != nil {
+		return nil, err
 	}
+	feed.SortByUpdated()
+	feed.RefreshUpdated()
 
-	HandleSuccess(w, r, feed)
+	return feed, nil
 }
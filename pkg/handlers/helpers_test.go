@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bossley9/feedme/pkg/api"
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+var testDate = time.Date(2022, 7, 4, 12, 34, 0, 0, time.UTC)
+
+func makeTestBuilder(updated time.Time) *atom.FeedBuilder {
+	return atom.NewFeedBuilder("example.com", "My Website", updated)
+}
+
+func makeTestFeed(t *testing.T) *atom.AtomFeed {
+	feed, err := makeTestBuilder(testDate).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return feed
+}
+
+func useValidationMode(t *testing.T, mode ValidationMode) {
+	defaultMode := validationMode
+	SetValidationMode(mode)
+	t.Cleanup(func() {
+		SetValidationMode(defaultMode)
+	})
+}
+
+// fixtureTransport responds to every request with a file from testdata.
+type fixtureTransport struct {
+	path string
+}
+
+func (transport fixtureTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	file, err := os.Open(transport.path)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{},
+		Body:       file,
+		Request:    r,
+	}, nil
+}
+
+// useFixture serves path as the response to every upstream request for the
+// rest of the test.
+func useFixture(t *testing.T, path string) {
+	client := api.NewClient()
+	client.HTTPClient.Transport = fixtureTransport{path}
+
+	defaultClient := api.DefaultClient
+	api.DefaultClient = client
+	t.Cleanup(func() {
+		api.DefaultClient = defaultClient
+	})
+}
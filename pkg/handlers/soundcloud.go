@@ -1,15 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"html"
-	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bossley9/feedme/pkg/api"
 	"github.com/bossley9/feedme/pkg/atom"
+	"github.com/bossley9/feedme/pkg/cache"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -118,14 +121,47 @@ type soundcloudResponse struct {
 	QueryUrn interface{} `json:"query_urn"`
 }
 
-func fetchSoundcloudClientID(htmlDoc *goquery.Document) (string, error) {
+type soundcloudTrack struct {
+	CommentCount int    `json:"comment_count"`
+	ID           int    `json:"id"`
+	PermalinkURL string `json:"permalink_url"`
+	Title        string `json:"title"`
+	Urn          string `json:"urn"`
+	User         struct {
+		PermalinkURL string `json:"permalink_url"`
+		Username     string `json:"username"`
+	} `json:"user"`
+}
+
+type soundcloudCommentsResponse struct {
+	Collection []struct {
+		Body      string    `json:"body"`
+		CreatedAt time.Time `json:"created_at"`
+		ID        int       `json:"id"`
+		Timestamp int       `json:"timestamp"` // position within the track in milliseconds
+		User      struct {
+			PermalinkURL string `json:"permalink_url"`
+			Username     string `json:"username"`
+		} `json:"user"`
+	} `json:"collection"`
+	NextHref string `json:"next_href"`
+}
+
+func getTrackURN(urn string, id int) string {
+	if len(urn) > 0 {
+		return urn
+	}
+	return "soundcloud:tracks:" + strconv.Itoa(id)
+}
+
+func fetchSoundcloudClientID(ctx context.Context, htmlDoc *goquery.Document) (string, error) {
 	// reliant on the fact that the last crossorigin script contains the client id
 	clientIDUrl, exists := htmlDoc.Find("script[crossorigin]").Last().Attr("src")
 	if !exists {
 		return "", errors.New("unable to find Soundcloud client id script source in document")
 	}
 
-	clientJSRaw, err := api.FetchGet(clientIDUrl)
+	clientJSRaw, err := api.FetchGetContext(ctx, clientIDUrl)
 	if err != nil {
 		return "", err
 	}
@@ -141,93 +177,275 @@ func fetchSoundcloudClientID(htmlDoc *goquery.Document) (string, error) {
 	return client_id_raw_2, nil
 }
 
-func HandleSoundcloud(w http.ResponseWriter, r *http.Request) {
-	user := r.FormValue("user")
-	if len(user) == 0 {
-		HandleUsage(w, r, "/soundcloud?user={USERNAME_FROM_URL}")
-		return
+// getNextOffset returns the offset of the next page of tracks, or an empty
+// string on the last page.
+func getNextOffset(nextHref string) string {
+	if len(nextHref) == 0 {
+		return ""
 	}
+	next, err := url.Parse(nextHref)
+	if err != nil {
+		return ""
+	}
+	return next.Query().Get("offset")
+}
 
-	formattedUrl := "https://soundcloud.com/" + user + "/tracks"
+type soundcloudSource struct{}
 
-	htmlDoc, err := api.FetchHTML(formattedUrl)
-	if err != nil {
-		HandleBadRequest(w, r, err)
-		return
+func (soundcloudSource) Name() string {
+	return "soundcloud"
+}
+
+func (soundcloudSource) Description() string {
+	return "SoundCloud tracks and track comments"
+}
+
+func (soundcloudSource) Params() []Param {
+	return []Param{
+		{Name: "user", Placeholder: "USERNAME_FROM_URL", Description: "username as shown in profile urls", Required: true},
+		{Name: "page", Placeholder: "PAGE", Description: "page linked from a previous page, repeated for every page before it"},
+		{Name: "track", Placeholder: "TRACK_FROM_URL", Description: "track name as shown in track urls, required for comments"},
+		{Name: "comments", Placeholder: "1", Description: "serve the comments of the track instead of tracks"},
 	}
+}
 
-	// display name might be different that url username
-	username := user
-	displayName, exists := htmlDoc.Find("meta[property='og:title']").Attr("content")
-	if exists {
-		username = displayName
+// track statistics and comments change often, so feeds are only cached
+// for 15 minutes
+func (soundcloudSource) CachePolicy() cache.Policy {
+	return cache.Policy{TTL: 15 * time.Minute, Stale: time.Hour}
+}
+
+func (soundcloudSource) Fetch(ctx context.Context, params Params) (*atom.AtomFeed, error) {
+	user := params.Get("user")
+	// pages are upstream offsets returned with each page. Upstream only
+	// links to the next page, so the offsets of every page before are kept
+	// to link back to the previous page.
+	pages := params.Values["page"]
+	page := ""
+	if len(pages) > 0 {
+		page = pages[len(pages)-1]
 	}
 
-	feed, err := atom.CreateFeed(formattedUrl, username, time.Now())
-	if err != nil {
-		HandleBadRequest(w, r, err)
-		return
+	if comments, _ := strconv.ParseBool(params.Get("comments")); comments {
+		return fetchSoundcloudComments(ctx, params, user, params.Get("track"), pages, page)
 	}
 
-	feed.AddLink(formattedUrl, atom.RelSelf)
+	formattedUrl := "https://soundcloud.com/" + user + "/tracks"
 
-	feed.AddAuthor(username, "", "")
-	feed.SetSubtitle(username+"'s Soundcloud tracks", "text")
+	htmlDoc, err := api.FetchHTMLContext(ctx, formattedUrl)
+	if err != nil {
+		return nil, badRequest(err)
+	}
 
-	image, exists := htmlDoc.Find("meta[property='og:image']").Attr("content")
+	// display name might be different that url username
+	username := user
+	displayName, exists := htmlDoc.Find("meta[property='og:title']").Attr("content")
 	if exists {
-		feed.SetLogo(image)
+		username = displayName
 	}
 
 	// get userID
 	userIDUrl, exists := htmlDoc.Find("meta[property='al:ios:url']").Attr("content")
 	if !exists {
-		HandleBadRequest(w, r, errors.New("unable to find Soundcloud user id in document"))
-		return
+		return nil, badRequest(errors.New("unable to find Soundcloud user id in document"))
 	}
 	userIDSegments := strings.Split(userIDUrl, ":")
 	userID := userIDSegments[len(userIDSegments)-1]
 
-	clientID, err := fetchSoundcloudClientID(htmlDoc)
+	// user ids remain the same when users are renamed
+	builder := atom.NewFeedBuilder(getStableID("soundcloud:users:"+userID), username, getGeneratedTime()).
+		Link(formattedUrl, atom.RelSelf).
+		Author(username, "", "").
+		Subtitle(username+"'s Soundcloud tracks", "text")
+
+	image, exists := htmlDoc.Find("meta[property='og:image']").Attr("content")
+	if exists {
+		builder.Logo(image)
+	}
+
+	clientID, err := fetchSoundcloudClientID(ctx, htmlDoc)
 	if err != nil {
-		HandleBadRequest(w, r, err)
-		return
+		return nil, badRequest(err)
 	}
 
 	// fetch data
-	data_url := "https://api-v2.soundcloud.com/users/" + userID + "/tracks?representation=&offset=&limit=30&client_id=" + clientID
-	data, err := api.FetchGet(data_url)
+	data_url := "https://api-v2.soundcloud.com/users/" + userID + "/tracks?representation=&offset=" + url.QueryEscape(page) + "&limit=30&client_id=" + clientID
+	data, err := api.FetchGetContext(ctx, data_url)
 	if err != nil {
-		HandleBadRequest(w, r, err)
-		return
+		return nil, badRequest(err)
 	}
 
 	var sc_json soundcloudResponse
 	json.Unmarshal(data, &sc_json)
 
+	setSoundcloudPaging(builder, params.URL, pages, sc_json.NextHref)
+
 	for _, track := range sc_json.Collection {
 		title := html.EscapeString(track.Title)
 
-		entry, err := atom.CreateFeedEntry(track.PermalinkURL, track.Title, track.LastModified)
+		trackURN := getTrackURN(track.Urn, track.ID)
+
+		var content strings.Builder
+		content.WriteString("<h2>" + title + " by " + html.EscapeString(track.User.Username) + "</h2>")
+		content.WriteString(`<img src="` + html.EscapeString(track.ArtworkURL) + `" alt="` + title + `" />`)
+		content.WriteString("<p>" + html.EscapeString(track.Description) + "</p>")
+		contentType := "html"
+		if atom.IsXHTML(content.String()) {
+			contentType = "xhtml"
+		}
+
+		entryBuilder := atom.NewEntryBuilder(getStableID(trackURN), track.Title, track.LastModified).
+			Author(track.User.Username, track.User.PermalinkURL, "").
+			Link(track.PermalinkURL, atom.RelAlternate).
+			Published(track.CreatedAt).
+			Content(content.String(), contentType)
+
+		// publisher metadata credits the performers and writers of a track,
+		// who are not necessarily the uploader
+		publisher := track.PublisherMetadata
+		if len(publisher.Artist) > 0 && publisher.Artist != track.User.Username {
+			entryBuilder.Contributor(publisher.Artist, "", "")
+		}
+		if len(publisher.WriterComposer) > 0 {
+			entryBuilder.Contributor(publisher.WriterComposer, "", "")
+		}
+		if len(publisher.CLineForDisplay) > 0 {
+			entryBuilder.Copyright(publisher.CLineForDisplay, "text")
+		}
+
+		if len(track.ArtworkURL) > 0 {
+			entryBuilder.Extension(atom.MediaThumbnail(track.ArtworkURL, 0, 0))
+		}
+		entryBuilder.Extension(atom.MediaStatistics(track.PlaybackCount))
+		if track.Duration > 0 {
+			entryBuilder.Extension(atom.ITunesDuration(time.Duration(track.Duration) * time.Millisecond))
+		}
+
+		if len(track.Genre) > 0 {
+			entryBuilder.Category(track.Genre, "", "")
+		}
+
+		if track.Commentable {
+			query := url.Values{}
+			query.Set("user", user)
+			query.Set("track", track.Permalink)
+			query.Set("comments", "1")
+			entryBuilder.
+				RepliesLink(getRequestURL(params.URL, query), "application/atom+xml", track.CommentCount, time.Time{}).
+				Total(track.CommentCount)
+		}
+
+		// tracks with invalid data are skipped
+		entry, err := entryBuilder.Build()
 		if err != nil {
 			continue
 		}
+		builder.Entry(entry)
+	}
 
-		entry.AddLink(track.PermalinkURL, atom.RelAlternate)
-		entry.SetPublished(track.CreatedAt)
+	feed, err := buildFeed(params, builder)
+	if err != nil {
+		return nil, err
+	}
+	// the newest track dates the feed, so unchanged feeds keep their
+	// validators across refreshes
+	feed.RefreshUpdated()
 
-		var content strings.Builder
-		content.WriteString("<h2>" + title + " by " + html.EscapeString(track.User.Username+"</h2>"))
-		content.WriteString(`<img src="` + track.ArtworkURL + `" alt="` + title + `" />`)
-		content.WriteString("<p>" + html.EscapeString(track.Description) + "</p>")
-		entry.SetContent(content.String(), "html")
+	return feed, nil
+}
 
-		if len(track.Genre) > 0 {
-			entry.AddCategory(track.Genre, "", "")
+// setSoundcloudPaging marks single pages as complete feeds and links every
+// other page to the first, previous and next pages. Upstream only returns
+// the offset of the next page, so the last page is unknown and never linked.
+func setSoundcloudPaging(builder *atom.FeedBuilder, requestURL *url.URL, pages []string, nextHref string) {
+	nextPage := getNextOffset(nextHref)
+	if len(pages) == 0 && len(nextPage) == 0 {
+		builder.Complete()
+		return
+	}
+
+	pageLinks := atom.PageLinks{First: getPageURL(requestURL, nil)}
+	if len(pages) > 0 {
+		pageLinks.Previous = getPageURL(requestURL, pages[:len(pages)-1])
+	}
+	if len(nextPage) > 0 {
+		nextPages := append(append([]string(nil), pages...), nextPage)
+		pageLinks.Next = getPageURL(requestURL, nextPages)
+	}
+	builder.PageLinks(pageLinks)
+}
+
+// fetchSoundcloudComments creates a feed of the comments of a track, each
+// threaded as a reply to the entry of the track.
+func fetchSoundcloudComments(ctx context.Context, params Params, user string, trackName string, pages []string, page string) (*atom.AtomFeed, error) {
+	if len(trackName) == 0 {
+		return nil, badRequest(errors.New("parameter 'track' is required for comments."))
+	}
+
+	trackUrl := "https://soundcloud.com/" + user + "/" + trackName
+
+	htmlDoc, err := api.FetchHTMLContext(ctx, trackUrl)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	clientID, err := fetchSoundcloudClientID(ctx, htmlDoc)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	trackData, err := api.FetchGetContext(ctx, "https://api-v2.soundcloud.com/resolve?url="+url.QueryEscape(trackUrl)+"&client_id="+clientID)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	var track soundcloudTrack
+	if err := json.Unmarshal(trackData, &track); err != nil || track.ID == 0 {
+		return nil, badRequest(errors.New("unable to find Soundcloud track " + trackUrl))
+	}
+	trackURN := getTrackURN(track.Urn, track.ID)
+
+	builder := atom.NewFeedBuilder(getStableID(trackURN+":comments"), "Comments on "+track.Title, getGeneratedTime()).
+		Link(getPageURL(params.URL, pages), atom.RelSelf).
+		Link(track.PermalinkURL, atom.RelAlternate).
+		Author(track.User.Username, track.User.PermalinkURL, "")
+
+	// fetch data
+	data_url := "https://api-v2.soundcloud.com/tracks/" + strconv.Itoa(track.ID) + "/comments?threaded=0&offset=" + url.QueryEscape(page) + "&limit=30&client_id=" + clientID
+	data, err := api.FetchGetContext(ctx, data_url)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	var sc_json soundcloudCommentsResponse
+	json.Unmarshal(data, &sc_json)
+
+	setSoundcloudPaging(builder, params.URL, pages, sc_json.NextHref)
+
+	tracksQuery := url.Values{}
+	tracksQuery.Set("user", user)
+	tracksUrl := getRequestURL(params.URL, tracksQuery)
+
+	for _, comment := range sc_json.Collection {
+		title := "Comment by " + comment.User.Username
+
+		entry, err := atom.NewEntryBuilder(getStableID("soundcloud:comments:"+strconv.Itoa(comment.ID)), title, comment.CreatedAt).
+			Author(comment.User.Username, comment.User.PermalinkURL, "").
+			Published(comment.CreatedAt).
+			Content(comment.Body, "text").
+			InReplyTo(getStableID(trackURN), track.PermalinkURL, "text/html", tracksUrl).
+			Build()
+		if err != nil {
+			continue
 		}
+		builder.Entry(entry)
+	}
 
-		feed.AddEntry(entry)
+	feed, err := buildFeed(params, builder)
+	if err != nil {
+		return nil, err
 	}
+	feed.RefreshUpdated()
 
-	HandleSuccess(w, r, feed)
+	return feed, nil
 }
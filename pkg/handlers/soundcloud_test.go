@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+func TestSetSoundcloudPaging(t *testing.T) {
+	requestURL, err := url.Parse("http://localhost/soundcloud?user=example&page=a&page=b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := makeTestBuilder(testDate)
+	setSoundcloudPaging(builder, requestURL, []string{"a", "b"}, "https://api-v2.soundcloud.com/users/1/tracks?offset=c")
+	feed, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	links := map[atom.AtomRelType]string{}
+	for _, link := range feed.Links {
+		links[link.Rel] = string(link.Href)
+	}
+	expected := map[atom.AtomRelType]string{
+		atom.RelFirst:    "http://localhost/soundcloud?user=example",
+		atom.RelPrevious: "http://localhost/soundcloud?page=a&user=example",
+		atom.RelNext:     "http://localhost/soundcloud?page=a&page=b&page=c&user=example",
+	}
+	for rel, href := range expected {
+		if links[rel] != href {
+			t.Errorf("Expected %s link %s, got %s", rel, href, links[rel])
+		}
+	}
+	if _, exists := links[atom.RelLast]; exists {
+		t.Error("Expected no last link")
+	}
+}
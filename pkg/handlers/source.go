@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bossley9/feedme/pkg/api"
+	"github.com/bossley9/feedme/pkg/atom"
+
+	"github.com/gorilla/mux"
+)
+
+// FeedSource generates feeds from an upstream service. Sources are served
+// under their name, so a source named "acast" is served at "/acast".
+type FeedSource interface {
+	Name() string
+	Description() string // one line summary shown in usage
+	Params() []Param
+	// Fetch generates the feed for the given parameters, which include every
+	// required parameter. Errors wrapped in RequestError are served as bad
+	// requests and any other error as an internal error. Sources build
+	// their feeds with Build, and feeds returned unvalidated are still
+	// checked or repaired by the validation mode.
+	Fetch(ctx context.Context, params Params) (*atom.AtomFeed, error)
+}
+
+// Param describes a query parameter of a feed source.
+type Param struct {
+	Name        string
+	Placeholder string // shown in usage as "name={PLACEHOLDER}"
+	Description string
+	Required    bool
+}
+
+// Params are the query parameters of a feed request. URL is the absolute
+// url of the request with only the parameters of the source, for sources
+// which link to themselves.
+type Params struct {
+	url.Values
+	URL     *url.URL
+	repairs *[]string // repairs made while building the feed
+}
+
+// RequestError reports a request which a source cannot serve, such as one
+// for an unknown user or an unreachable upstream service.
+type RequestError struct {
+	Err error
+}
+
+func (err RequestError) Error() string {
+	return err.Err.Error()
+}
+
+func (err RequestError) Unwrap() error {
+	return err.Err
+}
+
+func badRequest(err error) error {
+	return RequestError{err}
+}
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = map[string]FeedSource{}
+)
+
+func init() {
+	mustRegister(acastSource{})
+	mustRegister(geminiSource{})
+	mustRegister(soundcloudSource{})
+}
+
+// Register adds a feed source to every router created afterwards. Sources
+// are usually registered before the server is started.
+func Register(source FeedSource) error {
+	name := source.Name()
+	if len(name) == 0 || strings.ContainsAny(name, "/?#") {
+		return errors.New("feed source name '" + name + "' is invalid.")
+	}
+
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if _, exists := sources[name]; exists {
+		return errors.New("feed source '" + name + "' is already registered.")
+	}
+	sources[name] = source
+	return nil
+}
+
+func mustRegister(source FeedSource) {
+	if err := Register(source); err != nil {
+		panic(err)
+	}
+}
+
+func getSource(name string) (FeedSource, bool) {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	source, ok := sources[name]
+	return source, ok
+}
+
+// Sources lists every registered feed source ordered by name.
+func Sources() []FeedSource {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+
+	list := make([]FeedSource, 0, len(sources))
+	for _, source := range sources {
+		list = append(list, source)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list
+}
+
+// usage
+
+func getDefaultUsage() string {
+	var usage strings.Builder
+	usage.WriteString("/{type}?{param}={value}\n\navailable types are:\n")
+	for _, source := range Sources() {
+		usage.WriteString("* " + source.Name())
+		if description := source.Description(); len(description) > 0 {
+			usage.WriteString(" - " + description)
+		}
+		usage.WriteString("\n")
+	}
+	return usage.String()
+}
+
+// getSourceUsage describes the parameters of a source, with required
+// parameters first and optional parameters in brackets.
+func getSourceUsage(source FeedSource) string {
+	var usage strings.Builder
+	usage.WriteString("/" + source.Name())
+
+	params := source.Params()
+	separator := "?"
+	for _, required := range []bool{true, false} {
+		for _, param := range params {
+			if param.Required != required {
+				continue
+			}
+			pair := separator + param.Name + "={" + param.Placeholder + "}"
+			if !required {
+				pair = "[" + pair + "]"
+			}
+			usage.WriteString(pair)
+			separator = "&"
+		}
+	}
+	usage.WriteString("\n")
+
+	for _, param := range params {
+		if len(param.Description) > 0 {
+			usage.WriteString("\n  " + param.Name + ": " + param.Description)
+		}
+	}
+	return usage.String()
+}
+
+// handlers
+
+func handleFeed(w http.ResponseWriter, r *http.Request) {
+	source, ok := getSource(mux.Vars(r)["type"])
+	if !ok {
+		HandleNotFound(w, r)
+		return
+	}
+
+	// the body is left unread since some formats use it
+	query := r.URL.Query()
+	for _, param := range source.Params() {
+		if param.Required && len(query.Get(param.Name)) == 0 {
+			HandleUsage(w, r, getSourceUsage(source))
+			return
+		}
+	}
+
+	cached, err := fetchFeed(r.Context(), source, getSourceURL(r, source))
+	if err != nil {
+		var rateLimitErr api.RateLimitError
+		var tooLargeErr api.BodyTooLargeError
+		var requestErr RequestError
+		var buildErr atom.BuildError
+		switch {
+		case r.Context().Err() != nil:
+			// the client is gone
+		case errors.Is(err, context.DeadlineExceeded):
+			handleUpstreamError(w, r, http.StatusGatewayTimeout, err)
+		case errors.As(err, &rateLimitErr):
+			handleRateLimited(w, r, rateLimitErr)
+		case errors.As(err, &tooLargeErr):
+			handleUpstreamError(w, r, http.StatusBadGateway, err)
+		case errors.As(err, &requestErr):
+			HandleBadRequest(w, r, err)
+		case errors.As(err, &buildErr):
+			// upstream data which cannot be made into a valid feed
+			handleUpstreamError(w, r, http.StatusBadGateway, err)
+		default:
+			HandleInternalError(w, r, err)
+		}
+		return
+	}
+
+	serveFeed(w, r, cached)
+}
+
+// baseURL is where the server is reached, so feeds link to themselves the
+// same way whichever host name or proxy a request came through.
+var baseURL = url.URL{Scheme: "http", Host: "localhost:9000"}
+
+// SetBaseURL sets the scheme and host, with its port, of the urls feeds
+// link to themselves with.
+func SetBaseURL(scheme string, host string) {
+	baseURL = url.URL{Scheme: scheme, Host: host}
+}
+
+// getSourceURL returns the absolute url of the request with only the
+// parameters of the source, so requests for the same feed share a url.
+func getSourceURL(r *http.Request, source FeedSource) *url.URL {
+	query := r.URL.Query()
+	sourceQuery := url.Values{}
+	for _, param := range source.Params() {
+		// parameters may be repeated, such as the pages of SoundCloud feeds
+		for _, value := range query[param.Name] {
+			if len(value) > 0 {
+				sourceQuery.Add(param.Name, value)
+			}
+		}
+	}
+
+	sourceURL := url.URL{
+		Scheme:   baseURL.Scheme,
+		Host:     baseURL.Host,
+		Path:     "/" + source.Name(),
+		RawQuery: sourceQuery.Encode(),
+	}
+	return &sourceURL
+}
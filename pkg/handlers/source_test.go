@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetSourceURL_RepeatedPages(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/soundcloud?user=example&page=a&page=b&other=1", nil)
+
+	sourceURL := getSourceURL(r, soundcloudSource{})
+	if pages := sourceURL.Query()["page"]; len(pages) != 2 || pages[0] != "a" || pages[1] != "b" {
+		t.Errorf("Expected every page to be kept, got %v", pages)
+	}
+	if sourceURL.Query().Get("other") != "" {
+		t.Error("Expected parameters of other sources to be removed")
+	}
+}
+
+func TestGetSourceURL_BaseURL(t *testing.T) {
+	SetBaseURL("https", "feeds.example.com")
+	t.Cleanup(func() {
+		SetBaseURL("http", "localhost:9000")
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "http://alias.example.com/soundcloud?user=example", nil)
+
+	if sourceURL := getSourceURL(r, soundcloudSource{}).String(); sourceURL != "https://feeds.example.com/soundcloud?user=example" {
+		t.Errorf("Expected url of the configured server, got %s", sourceURL)
+	}
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"bytes"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/bossley9/feedme/pkg/api"
 	"github.com/bossley9/feedme/pkg/atom"
+	"github.com/bossley9/feedme/pkg/date"
 
 	"github.com/gorilla/mux"
 )
@@ -23,11 +29,32 @@ func HandleUnimplemented(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintln(w, "Not yet implemented.")
 }
 
+func HandleInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprintln(w, err)
+}
+
 func HandleBadRequest(w http.ResponseWriter, r *http.Request, err error) {
 	w.WriteHeader(http.StatusBadRequest)
 	fmt.Fprintln(w, err)
 }
 
+// handleUpstreamError reports an upstream service which failed to respond
+// in time or in full.
+func handleUpstreamError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	w.WriteHeader(status)
+	fmt.Fprintln(w, err)
+}
+
+// handleRateLimited asks the client to retry once the upstream service
+// allows requests again.
+func handleRateLimited(w http.ResponseWriter, r *http.Request, err api.RateLimitError) {
+	seconds := int(time.Until(err.Until).Seconds()) + 1
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	fmt.Fprintln(w, err)
+}
+
 // usage
 
 func HandleUsage(w http.ResponseWriter, r *http.Request, usage string) {
@@ -41,19 +68,112 @@ func HandleDefaultUsage(w http.ResponseWriter, r *http.Request) {
 
 // success
 
-func HandleSuccess(w http.ResponseWriter, _ *http.Request, feed *atom.AtomFeed) {
+// serveFeed writes the feed in the requested format, or only its headers
+// when the client already has it. The feed is only read, so cached feeds
+// can be served concurrently.
+func serveFeed(w http.ResponseWriter, r *http.Request, cached *cachedFeed) {
+	feed := cached.feed
+
+	format, err := getFormat(r)
+	if err != nil {
+		HandleBadRequest(w, r, err)
+		return
+	}
+
+	if !checkFeed(w, r, feed, cached.repairs) {
+		return
+	}
+
+	// encode before writing headers so errors are not sent as a truncated
+	// successful response
+	var body bytes.Buffer
+	if format == diffFormat {
+		// the previous snapshot is sent by the client
+		r.Body = http.MaxBytesReader(w, r.Body, maxSnapshotSize)
+		if err := encodeDiff(&body, r, feed); err != nil {
+			HandleBadRequest(w, r, err)
+			return
+		}
+	} else if err := encodeFeed(&body, feed, format); err != nil {
+		HandleInternalError(w, r, err)
+		return
+	}
+
+	// every header must be set before the status is written
+	w.Header().Set("Vary", "Accept")
+	if format != diffFormat {
+		etag := getETag(body.Bytes())
+		lastModified := time.Time(feed.Updated)
+		setValidators(w, etag, lastModified, cached.expires)
+		if isNotModified(r, etag, lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", format.mediaType)
+	w.Header().Set("Content-Disposition", "inline; filename=\""+format.filename+"\"")
 	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/atom+xml")
-	w.Header().Set("Content-Disposition", "inline; filename=\"feed.xml\"")
-	fmt.Fprintln(w, feed.String())
+	body.WriteTo(w)
+}
+
+// paging
+
+// getPageURL returns the absolute url of the request with the page
+// parameter set to pages, or removed when pages is empty.
+func getPageURL(requestURL *url.URL, pages []string) string {
+	query := requestURL.Query()
+	if len(pages) > 0 {
+		query["page"] = pages
+	} else {
+		query.Del("page")
+	}
+	return getRequestURL(requestURL, query)
+}
+
+// getRequestURL returns the absolute url of the request with the given
+// query parameters.
+func getRequestURL(requestURL *url.URL, query url.Values) string {
+	absoluteURL := *requestURL
+	absoluteURL.RawQuery = query.Encode()
+	return absoluteURL.String()
+}
+
+// ids
+
+// idNamespace scopes the UUIDs derived from upstream identifiers to feedme
+var idNamespace = atom.NewUUIDv5(atom.NamespaceURL, atom.PKG)
+
+// getStableID derives an atom:id from an immutable upstream identifier such
+// as "soundcloud:tracks:123", so ids survive changes to titles and urls.
+func getStableID(upstreamID string) string {
+	return atom.NewUUIDv5(idNamespace, upstreamID).URN()
 }
 
 // date
 
-func getDatetime(date string, format string) time.Time {
-	datetime, err := time.Parse(format, date)
+// dateLocation is the location dates of served feeds are written in, or nil
+// to keep the offsets dates were generated with.
+var dateLocation *time.Location
+
+// SetDateLocation writes every date of served feeds in loc, such as
+// time.UTC. A nil location keeps the offsets dates were generated with.
+func SetDateLocation(loc *time.Location) {
+	dateLocation = loc
+}
+
+// getGeneratedTime returns the current time to the second, for feeds which
+// are dated when they are generated.
+func getGeneratedTime() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+// getDatetime parses an upstream date, logging and returning the fallback
+// when the date cannot be parsed.
+func getDatetime(text string, fallback time.Time) time.Time {
+	datetime, err := date.Parse(text)
 	if err != nil {
-		datetime = time.Now()
+		log.Printf("%v, using %s instead", err, fallback.Format(time.RFC3339))
+		return fallback
 	}
 	return datetime
 }
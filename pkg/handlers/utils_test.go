@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+func TestServeFeed(t *testing.T) {
+	feed := makeTestFeed(t)
+
+	w := httptest.NewRecorder()
+	serveFeed(w, httptest.NewRequest(http.MethodGet, "/test", nil), &cachedFeed{feed: feed})
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != atomFormat.mediaType {
+		t.Errorf("Expected content type %s, got %s", atomFormat.mediaType, contentType)
+	}
+	if w.Body.String() != feed.String() {
+		t.Errorf("Expected feed to be served, got:\n%s", w.Body)
+	}
+}
+
+func TestServeFeed_EncodeError(t *testing.T) {
+	feed := makeTestFeed(t)
+	// elements without a name cannot be encoded
+	feed.Extensions = append(feed.Extensions, atom.AtomExtension{XMLName: xml.Name{}})
+
+	w := httptest.NewRecorder()
+	serveFeed(w, httptest.NewRequest(http.MethodGet, "/test", nil), &cachedFeed{feed: feed})
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType == atomFormat.mediaType {
+		t.Error("Expected failed feed not to be served as a feed")
+	}
+}
+
+func TestServeFeed_Diff(t *testing.T) {
+	previous := makeTestFeed(t)
+	feed := makeTestFeed(t)
+	entry, err := atom.CreateFeedEntry("example.com/entry/1", "Entry 1", time.Time(feed.Updated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed.AddEntry(entry)
+
+	// curl --data-binary sends the snapshot as a form
+	r := httptest.NewRequest(http.MethodPost, "/test?format=diff", strings.NewReader(previous.String()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	serveFeed(w, r, &cachedFeed{feed: feed})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), `"id": "example.com/entry/1"`) {
+		t.Errorf("Expected entry to be added, got:\n%s", w.Body)
+	}
+}
+
+func TestServeFeed_DiffTooLarge(t *testing.T) {
+	feed := makeTestFeed(t)
+
+	padding := "<!--" + strings.Repeat(" ", maxSnapshotSize) + "-->"
+	body := strings.Replace(feed.String(), "<id>", padding+"<id>", 1)
+	r := httptest.NewRequest(http.MethodPost, "/test?format=diff", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	serveFeed(w, r, &cachedFeed{feed: feed})
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+// ValidationMode determines how feeds are validated before being served.
+type ValidationMode string
+
+const (
+	ValidationOff    ValidationMode = "off"    // serve feeds as generated
+	ValidationStrict ValidationMode = "strict" // reject invalid feeds
+	ValidationRepair ValidationMode = "repair" // repair feeds where possible
+)
+
+var validationMode = ValidationRepair
+
+func ParseValidationMode(name string) (ValidationMode, error) {
+	switch mode := ValidationMode(name); mode {
+	case ValidationOff, ValidationStrict, ValidationRepair:
+		return mode, nil
+	}
+	return "", errors.New("validation mode '" + name + "' not found.")
+}
+
+// SetValidationMode sets the validation mode of every served feed.
+func SetValidationMode(mode ValidationMode) {
+	validationMode = mode
+}
+
+// buildFeed builds the feed of a source with Build, which rejects invalid
+// feeds. In repair mode the feed is repaired first and the repairs are kept
+// in params, while feeds are built unvalidated when validation is off.
+func buildFeed(params Params, builder *atom.FeedBuilder) (*atom.AtomFeed, error) {
+	switch validationMode {
+	case ValidationOff:
+		return builder.BuildUnvalidated()
+	case ValidationRepair:
+		repairs := builder.Repair()
+		if params.repairs != nil {
+			*params.repairs = append(*params.repairs, repairs...)
+		}
+	}
+	return builder.Build()
+}
+
+// repairFeed repairs the feed in repair mode, logging the repairs made, with
+// those made while building, and any problems which remain.
+func repairFeed(name string, feed *atom.AtomFeed, repairs []string) []string {
+	if validationMode != ValidationRepair {
+		return nil
+	}
+
+	repairs = append(repairs, feed.Repair()...)
+	if len(repairs) > 0 {
+		log.Printf("repaired feed %s:\n%s", name, strings.Join(repairs, "\n"))
+	}
+	if report := feed.Report(); !report.Valid() {
+		log.Printf("serving feed %s with unrepaired problems:\n%s", name, report)
+	}
+	return repairs
+}
+
+// checkFeed rejects invalid feeds in strict mode and lists the repairs made
+// by buildFeed and repairFeed in repair mode. It returns false when the feed
+// was rejected and a response was written.
+func checkFeed(w http.ResponseWriter, r *http.Request, feed *atom.AtomFeed, repairs []string) bool {
+	switch validationMode {
+	case ValidationStrict:
+		report := feed.Report()
+		if !report.Valid() {
+			log.Printf("rejected invalid feed %s:\n%s", r.URL, report)
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "the generated feed is invalid:\n"+report.String())
+			return false
+		}
+
+	case ValidationRepair:
+		if len(repairs) > 0 {
+			w.Header().Set("X-Feed-Repairs", strings.Join(repairs, "; "))
+		}
+	}
+
+	return true
+}
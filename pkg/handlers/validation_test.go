@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+// repairableSource builds a feed which Build rejects but Repair fixes.
+type repairableSource struct{}
+
+func (repairableSource) Name() string        { return "repairable" }
+func (repairableSource) Description() string { return "" }
+func (repairableSource) Params() []Param     { return nil }
+
+func (repairableSource) Fetch(ctx context.Context, params Params) (*atom.AtomFeed, error) {
+	builder := makeTestBuilder(testDate).
+		Author("", "", "owner@example.com").
+		Subtitle("", "text")
+	return buildFeed(params, builder)
+}
+
+func TestBuildFeed(t *testing.T) {
+	SetCacheSize(0)
+	t.Cleanup(func() {
+		SetCacheSize(256)
+	})
+	requestURL := &url.URL{Path: "/repairable"}
+
+	useValidationMode(t, ValidationStrict)
+	if _, err := fetchFeed(context.Background(), repairableSource{}, requestURL); err == nil {
+		t.Error("Expected invalid feed to be rejected in strict mode")
+	}
+
+	useValidationMode(t, ValidationRepair)
+	cached, err := fetchFeed(context.Background(), repairableSource{}, requestURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached.repairs) == 0 {
+		t.Error("Expected repairs made while building to be listed")
+	}
+	if report := cached.feed.Report(); !report.Valid() {
+		t.Errorf("Expected repaired feed to be valid, got:\n%s", report)
+	}
+}
+
+func TestRepairFeed(t *testing.T) {
+	useValidationMode(t, ValidationRepair)
+
+	// a nameless author and an empty subtitle are rejected but repairable
+	builder := makeTestBuilder(testDate).
+		Author("", "", "owner@example.com").
+		Subtitle("", "text")
+	if _, err := builder.Build(); err == nil {
+		t.Fatal("Expected Build to reject the feed")
+	}
+	feed, err := builder.BuildUnvalidated()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+	serveFeed(w, r, &cachedFeed{feed: feed, repairs: repairFeed("test", feed, nil)})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	if len(w.Header().Get("X-Feed-Repairs")) == 0 {
+		t.Error("Expected repairs to be listed")
+	}
+	if report := feed.Report(); !report.Valid() {
+		t.Errorf("Expected repaired feed to be valid, got:\n%s", report)
+	}
+}
+
+func TestCheckFeed_Strict(t *testing.T) {
+	useValidationMode(t, ValidationStrict)
+
+	feed, err := makeTestBuilder(testDate).
+		Author("", "", "owner@example.com").
+		Subtitle("", "text").
+		BuildUnvalidated()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+	serveFeed(w, r, &cachedFeed{feed: feed, repairs: repairFeed("test", feed, nil)})
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status %d, got %d", http.StatusBadGateway, w.Code)
+	}
+}
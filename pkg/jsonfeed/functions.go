@@ -0,0 +1,128 @@
+package jsonfeed
+
+import (
+	"time"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+// FromAtom converts an Atom feed into a JSON Feed. Categories become tags
+// and enclosure links become attachments.
+func FromAtom(feed *atom.AtomFeed) JSONFeed {
+	jsonFeed := JSONFeed{
+		Version:     Version,
+		Title:       atom.AtomTextConstruct(feed.Title).PlainText(),
+		HomePageURL: findLink(feed.Links, atom.RelAlternate),
+		FeedURL:     findLink(feed.Links, atom.RelSelf),
+		Icon:        string(feed.Icon),
+		Authors:     createAuthors(feed.Authors),
+		Language:    string(feed.Lang),
+		Items:       []Item{},
+	}
+
+	if feed.Subtitle != nil {
+		jsonFeed.Description = atom.AtomTextConstruct(*feed.Subtitle).PlainText()
+	}
+
+	for _, entry := range feed.Entries {
+		jsonFeed.Items = append(jsonFeed.Items, createItem(entry))
+	}
+
+	return jsonFeed
+}
+
+func createItem(entry atom.AtomEntry) Item {
+	item := Item{
+		ID:           string(entry.Id),
+		URL:          findLink(entry.Links, atom.RelAlternate),
+		Title:        atom.AtomTextConstruct(entry.Title).PlainText(),
+		DateModified: formatDate(time.Time(entry.Updated)),
+		Authors:      createAuthors(entry.Authors),
+		Language:     string(entry.Lang),
+	}
+
+	if entry.Published != nil {
+		item.DatePublished = formatDate(time.Time(*entry.Published))
+	}
+
+	if len(item.Authors) == 0 && entry.Source != nil {
+		item.Authors = createAuthors(entry.Source.Authors)
+	}
+
+	var summary *atom.AtomTextConstruct
+	if entry.Summary != nil {
+		text := atom.AtomTextConstruct(*entry.Summary)
+		summary = &text
+		item.Summary = text.PlainText()
+	}
+
+	// items require content, so summaries stand in for missing content
+	content := summary
+	if entry.Content != nil && len(entry.Content.Src) == 0 {
+		content = &atom.AtomTextConstruct{
+			Type: atom.AtomTextType(entry.Content.Type),
+			Text: entry.Content.Text,
+		}
+	}
+	if content != nil {
+		if content.Type == "html" || content.Type == "xhtml" {
+			item.ContentHTML = content.HTML()
+		} else {
+			item.ContentText = content.Text
+		}
+	}
+	if len(item.ContentHTML) == 0 && len(item.ContentText) == 0 {
+		item.ContentText = item.Title
+	}
+
+	for _, cat := range entry.Categories {
+		item.Tags = append(item.Tags, cat.Term)
+	}
+
+	for _, link := range entry.Links {
+		if link.Rel != atom.RelEnclosure {
+			continue
+		}
+		mimeType := string(link.Type)
+		if len(mimeType) == 0 {
+			mimeType = "application/octet-stream"
+		}
+		item.Attachments = append(item.Attachments, Attachment{
+			URL:         string(link.Href),
+			MimeType:    mimeType,
+			Title:       link.Title,
+			SizeInBytes: link.Length,
+		})
+	}
+
+	return item
+}
+
+func createAuthors(authors []atom.AtomAuthor) []Author {
+	var jsonAuthors []Author
+	for _, author := range authors {
+		jsonAuthors = append(jsonAuthors, Author{
+			Name: string(author.Name),
+			URL:  string(author.Uri),
+		})
+	}
+	return jsonAuthors
+}
+
+// findLink returns the first link with the given relation, treating links
+// without a relation as alternate links.
+func findLink(links []atom.AtomLink, rel atom.AtomRelType) string {
+	for _, link := range links {
+		if link.Rel == rel || (rel == atom.RelAlternate && link.Rel == atom.RelUnknown) {
+			return string(link.Href)
+		}
+	}
+	return ""
+}
+
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,44 @@
+// see https://www.jsonfeed.org/version/1.1/
+
+package jsonfeed
+
+const Version = "https://jsonfeed.org/version/1.1"
+
+type JSONFeed struct {
+	Version     string   `json:"version"` // required
+	Title       string   `json:"title"`   // required
+	HomePageURL string   `json:"home_page_url,omitempty"`
+	FeedURL     string   `json:"feed_url,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	Authors     []Author `json:"authors,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Items       []Item   `json:"items"` // required
+}
+
+type Item struct {
+	ID            string       `json:"id"` // required
+	URL           string       `json:"url,omitempty"`
+	Title         string       `json:"title,omitempty"`
+	ContentHTML   string       `json:"content_html,omitempty"` // content_html or content_text required
+	ContentText   string       `json:"content_text,omitempty"`
+	Summary       string       `json:"summary,omitempty"`
+	DatePublished string       `json:"date_published,omitempty"` // RFC 3339
+	DateModified  string       `json:"date_modified,omitempty"`  // RFC 3339
+	Authors       []Author     `json:"authors,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+	Attachments   []Attachment `json:"attachments,omitempty"`
+	Language      string       `json:"language,omitempty"`
+}
+
+type Author struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type Attachment struct {
+	URL         string `json:"url"`       // required
+	MimeType    string `json:"mime_type"` // required
+	Title       string `json:"title,omitempty"`
+	SizeInBytes uint   `json:"size_in_bytes,omitempty"`
+}
@@ -0,0 +1,131 @@
+package jsonfeed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+func assertEqual(t *testing.T, test string, ref string) {
+	if test != ref {
+		t.Errorf("Expected %s to equal %s", test, ref)
+	}
+}
+
+func makeTestFeed(t *testing.T) *atom.AtomFeed {
+	date, errDate := time.Parse("2006-01-02 15:04", "2022-07-04 12:34")
+	if errDate != nil {
+		t.Error(errDate)
+	}
+	feed, errCreateFeed := atom.CreateFeed("example.com", "My Website", date)
+	if errCreateFeed != nil {
+		t.Error(errCreateFeed)
+	}
+	return feed
+}
+
+func makeTestEntry(t *testing.T) *atom.AtomEntry {
+	date, errDate := time.Parse("2006-01-02 15:04", "2022-07-04 12:34")
+	if errDate != nil {
+		t.Error(errDate)
+	}
+	entry, errCreateEntry := atom.CreateFeedEntry("example.com/entry/1", "Entry 1", date)
+	if errCreateEntry != nil {
+		t.Error(errCreateEntry)
+	}
+	return entry
+}
+
+func TestFromAtom_Format(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddAuthor("John Doe", "https://johndoe.com", "")
+	feed.AddLink("https://example.com/feed", atom.RelSelf)
+	feed.AddLink("https://example.com", atom.RelAlternate)
+	feed.SetSubtitle("A website", "text")
+
+	entry := makeTestEntry(t)
+	entry.AddLink("https://example.com/1", atom.RelAlternate)
+	entry.AddLinkWithOptions("https://example.com/1.mp3", atom.RelEnclosure, atom.LinkOptions{
+		Type:   "audio/mpeg",
+		Length: 1337,
+	})
+	entry.SetSummary("<em>An</em> episode", "html")
+	entry.SetContent("<p>Hello & welcome</p>", "html")
+	entry.AddCategory("cats", "", "")
+	published, _ := time.Parse(time.RFC3339, "2022-07-01T08:00:00-02:00")
+	entry.SetPublished(published)
+	feed.AddEntry(entry)
+
+	ref := `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "My Website",
+  "home_page_url": "https://example.com",
+  "feed_url": "https://example.com/feed",
+  "description": "A website",
+  "authors": [
+    {
+      "name": "John Doe",
+      "url": "https://johndoe.com"
+    }
+  ],
+  "items": [
+    {
+      "id": "example.com/entry/1",
+      "url": "https://example.com/1",
+      "title": "Entry 1",
+      "content_html": "<p>Hello & welcome</p>",
+      "summary": "An episode",
+      "date_published": "2022-07-01T08:00:00-02:00",
+      "date_modified": "2022-07-04T12:34:00Z",
+      "tags": [
+        "cats"
+      ],
+      "attachments": [
+        {
+          "url": "https://example.com/1.mp3",
+          "mime_type": "audio/mpeg",
+          "size_in_bytes": 1337
+        }
+      ]
+    }
+  ]
+}`
+
+	assertEqual(t, FromAtom(feed).String(), ref)
+}
+
+func TestFromAtom_EmptyFeed(t *testing.T) {
+	feed := makeTestFeed(t)
+	ref := `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "My Website",
+  "items": []
+}`
+
+	assertEqual(t, FromAtom(feed).String(), ref)
+}
+
+func TestFromAtom_TextContent(t *testing.T) {
+	feed := makeTestFeed(t)
+	entry := makeTestEntry(t)
+	entry.SetSummary("Just text", "text")
+	feed.AddEntry(entry)
+
+	item := FromAtom(feed).Items[0]
+	assertEqual(t, item.ContentText, "Just text")
+	assertEqual(t, item.ContentHTML, "")
+}
+
+func TestFromAtom_Language(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.SetLanguage("en")
+	entry := makeTestEntry(t)
+	entry.SetLanguage("fr")
+	feed.AddEntry(entry)
+
+	jsonFeed := FromAtom(feed)
+
+	assertEqual(t, jsonFeed.Language, "en")
+	assertEqual(t, jsonFeed.Items[0].Language, "fr")
+}
@@ -0,0 +1,22 @@
+package jsonfeed
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Encode writes the feed to w as indented JSON followed by a newline.
+func (feed JSONFeed) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(feed)
+}
+
+func (feed JSONFeed) String() string {
+	var out strings.Builder
+	// silently ignore errors
+	feed.Encode(&out)
+	return strings.TrimSuffix(out.String(), "\n")
+}
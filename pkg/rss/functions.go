@@ -0,0 +1,184 @@
+package rss
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+// FromAtom converts an Atom feed into an RSS 2.0 document. Atom ids become
+// guids, enclosure links become enclosures, summaries and content become
+// descriptions and encoded content, and authors become dc:creator elements.
+func FromAtom(feed *atom.AtomFeed) RSS {
+	channel := Channel{
+		Title:         atom.AtomTextConstruct(feed.Title).PlainText(),
+		Link:          findChannelLink(feed),
+		Language:      string(feed.Lang),
+		LastBuildDate: Date(feed.Updated),
+		Generator:     feed.Generator.Text,
+	}
+	if len(feed.Generator.Version) > 0 {
+		channel.Generator += " " + feed.Generator.Version
+	}
+
+	channel.Description = channel.Title
+	if feed.Subtitle != nil {
+		channel.Description = atom.AtomTextConstruct(*feed.Subtitle).PlainText()
+	}
+
+	if feed.Rights != nil {
+		channel.Copyright = atom.AtomTextConstruct(*feed.Rights).PlainText()
+	}
+
+	for _, cat := range feed.Categories {
+		channel.Categories = append(channel.Categories, createCategory(cat))
+	}
+
+	if len(feed.Logo) > 0 {
+		channel.Image = &Image{
+			URL:   string(feed.Logo),
+			Title: channel.Title,
+			Link:  channel.Link,
+		}
+	}
+
+	for _, link := range feed.Links {
+		if link.Rel == atom.RelSelf {
+			channel.SelfLink = &AtomLink{
+				Href: string(link.Href),
+				Rel:  "self",
+				Type: "application/rss+xml",
+			}
+			break
+		}
+	}
+
+	var feedCreators []string
+	for _, author := range feed.Authors {
+		feedCreators = append(feedCreators, string(author.Name))
+	}
+
+	for _, entry := range feed.Entries {
+		channel.Items = append(channel.Items, createItem(entry, feedCreators))
+	}
+
+	return RSS{
+		Version:   "2.0",
+		ContentNS: contentNamespace,
+		DCNS:      dcNamespace,
+		AtomNS:    atomNamespace,
+		Channel:   channel,
+	}
+}
+
+func createItem(entry atom.AtomEntry, feedCreators []string) Item {
+	item := Item{
+		Title: atom.AtomTextConstruct(entry.Title).PlainText(),
+		Link:  findLink(entry.Links, ""),
+	}
+
+	// ids are only permalinks when they can be dereferenced
+	id := string(entry.Id)
+	item.Guid = &Guid{
+		IsPermaLink: id == item.Link && isHttpURL(id),
+		Text:        id,
+	}
+
+	if entry.Summary != nil {
+		item.Description = atom.AtomTextConstruct(*entry.Summary).HTML()
+	}
+
+	if entry.Content != nil && len(entry.Content.Src) == 0 {
+		content := atom.AtomTextConstruct{
+			Type: atom.AtomTextType(entry.Content.Type),
+			Text: entry.Content.Text,
+		}
+		if isTextType(content.Type) {
+			item.ContentEncoded = content.HTML()
+			if len(item.Description) == 0 {
+				item.Description = item.ContentEncoded
+			}
+		}
+	}
+
+	for _, author := range entry.Authors {
+		item.Creators = append(item.Creators, string(author.Name))
+	}
+	if len(item.Creators) == 0 && entry.Source != nil {
+		for _, author := range entry.Source.Authors {
+			item.Creators = append(item.Creators, string(author.Name))
+		}
+	}
+	if len(item.Creators) == 0 {
+		item.Creators = feedCreators
+	}
+
+	for _, cat := range entry.Categories {
+		item.Categories = append(item.Categories, createCategory(cat))
+	}
+
+	for _, link := range entry.Links {
+		if link.Rel == atom.RelEnclosure {
+			item.Enclosure = &Enclosure{
+				URL:    string(link.Href),
+				Length: link.Length,
+				Type:   string(link.Type),
+			}
+			break
+		}
+	}
+
+	date := Date(entry.Updated)
+	if entry.Published != nil {
+		date = Date(*entry.Published)
+	}
+	if !time.Time(date).IsZero() {
+		item.PubDate = &date
+	}
+
+	return item
+}
+
+func createCategory(cat atom.AtomCategory) Category {
+	return Category{
+		Domain: string(cat.Scheme),
+		Text:   cat.Term,
+	}
+}
+
+// findLink returns the first alternate link, or fallback if none exist.
+func findLink(links []atom.AtomLink, fallback string) string {
+	for _, link := range links {
+		if link.Rel == atom.RelAlternate || link.Rel == atom.RelUnknown {
+			return string(link.Href)
+		}
+	}
+	return fallback
+}
+
+// findChannelLink returns the alternate link of a feed, falling back to its
+// id when it is a web address and then to its self link, since ids such as
+// urn:uuid are not valid channel links.
+func findChannelLink(feed *atom.AtomFeed) string {
+	if link := findLink(feed.Links, ""); len(link) > 0 {
+		return link
+	}
+	if isHttpURL(string(feed.Id)) {
+		return string(feed.Id)
+	}
+	for _, link := range feed.Links {
+		if link.Rel == atom.RelSelf {
+			return string(link.Href)
+		}
+	}
+	return ""
+}
+
+func isHttpURL(str string) bool {
+	return strings.HasPrefix(str, "http://") || strings.HasPrefix(str, "https://")
+}
+
+func isTextType(textType atom.AtomTextType) bool {
+	return textType == "" || textType == "text" || textType == "html" || textType == "xhtml"
+}
@@ -0,0 +1,84 @@
+// see https://www.rssboard.org/rss-specification
+
+package rss
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const contentNamespace = "http://purl.org/rss/1.0/modules/content/"
+const dcNamespace = "http://purl.org/dc/elements/1.1/"
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type RSS struct {
+	XMLName   xml.Name `xml:"rss"`
+	Version   string   `xml:"version,attr"`
+	ContentNS string   `xml:"xmlns:content,attr"`
+	DCNS      string   `xml:"xmlns:dc,attr"`
+	AtomNS    string   `xml:"xmlns:atom,attr"`
+	Channel   Channel  `xml:"channel"`
+}
+
+type Channel struct {
+	Title         string     `xml:"title"`          // required
+	Link          string     `xml:"link,omitempty"` // required, omitted when unknown
+	Description   string     `xml:"description"`    // required
+	Language      string     `xml:"language,omitempty"`
+	Copyright     string     `xml:"copyright,omitempty"`
+	LastBuildDate Date       `xml:"lastBuildDate"`
+	Categories    []Category `xml:"category"`
+	Generator     string     `xml:"generator,omitempty"`
+	Image         *Image     `xml:"image,omitempty"`
+	SelfLink      *AtomLink  `xml:"atom:link,omitempty"` // recommended for feed readers
+	Items         []Item     `xml:"item"`
+}
+
+type Item struct {
+	XMLName        xml.Name   `xml:"item"`
+	Title          string     `xml:"title,omitempty"` // title or description required
+	Link           string     `xml:"link,omitempty"`
+	Description    string     `xml:"description,omitempty"`
+	ContentEncoded string     `xml:"content:encoded,omitempty"`
+	Creators       []string   `xml:"dc:creator"`
+	Categories     []Category `xml:"category"`
+	Enclosure      *Enclosure `xml:"enclosure,omitempty"`
+	Guid           *Guid      `xml:"guid,omitempty"`
+	PubDate        *Date      `xml:"pubDate,omitempty"`
+}
+
+type Category struct {
+	Domain string `xml:"domain,attr,omitempty"`
+	Text   string `xml:",chardata"`
+}
+
+type Image struct {
+	URL   string `xml:"url"`
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+// self-closing element
+type Enclosure struct {
+	URL    string `xml:"url,attr"`
+	Length uint   `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type Guid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Text        string `xml:",chardata"`
+}
+
+// self-closing element
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type Date time.Time // datetime reference as defined in RFC 822
+
+func (date Date) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(time.Time(date).Format(time.RFC1123Z), start)
+}
@@ -0,0 +1,150 @@
+package rss
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/bossley9/feedme/pkg/atom"
+)
+
+func assertEqual(t *testing.T, test string, ref string) {
+	if test != ref {
+		t.Errorf("Expected %s to equal %s", test, ref)
+	}
+}
+
+func makeTestFeed(t *testing.T) *atom.AtomFeed {
+	date, errDate := time.Parse("2006-01-02 15:04", "2022-07-04 12:34")
+	if errDate != nil {
+		t.Error(errDate)
+	}
+	feed, errCreateFeed := atom.CreateFeed("example.com", "My Website", date)
+	if errCreateFeed != nil {
+		t.Error(errCreateFeed)
+	}
+	return feed
+}
+
+func makeTestEntry(t *testing.T) *atom.AtomEntry {
+	date, errDate := time.Parse("2006-01-02 15:04", "2022-07-04 12:34")
+	if errDate != nil {
+		t.Error(errDate)
+	}
+	entry, errCreateEntry := atom.CreateFeedEntry("urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a", "Entry <1>", date)
+	if errCreateEntry != nil {
+		t.Error(errCreateEntry)
+	}
+	return entry
+}
+
+func TestFromAtom_Channel(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddAuthor("John Doe", "", "")
+	feed.AddLink("https://example.com/feed", atom.RelSelf)
+	feed.AddLink("https://example.com", atom.RelAlternate)
+	feed.SetSubtitle("<em>Formatted</em> subtitle", "html")
+	feed.SetCopyright("CC BY 4.0", "text")
+	feed.SetLogo("https://example.com/logo.png")
+	feed.AddCategory("cats", "", "")
+
+	ref := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>My Website</title>
+    <link>https://example.com</link>
+    <description>Formatted subtitle</description>
+    <copyright>CC BY 4.0</copyright>
+    <lastBuildDate>Mon, 04 Jul 2022 12:34:00 +0000</lastBuildDate>
+    <category>cats</category>
+    <generator>Generated via ` + atom.NAME + " " + atom.VERSION + `</generator>
+    <image>
+      <url>https://example.com/logo.png</url>
+      <title>My Website</title>
+      <link>https://example.com</link>
+    </image>
+    <atom:link href="https://example.com/feed" rel="self" type="application/rss+xml"></atom:link>
+  </channel>
+</rss>`
+
+	assertEqual(t, FromAtom(feed).String(), ref)
+}
+
+func TestFromAtom_Item(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.AddAuthor("John Doe", "", "")
+
+	entry := makeTestEntry(t)
+	entry.AddLink("https://example.com/1", atom.RelAlternate)
+	entry.AddLinkWithOptions("https://example.com/1.mp3", atom.RelEnclosure, atom.LinkOptions{
+		Type:   "audio/mpeg",
+		Length: 1337,
+	})
+	entry.SetSummary("Fish & chips", "text")
+	entry.SetContent("<p>Hello</p>", "xhtml")
+	entry.AddCategory("food", "https://example.com/tags", "")
+	published, _ := time.Parse(time.RFC3339, "2022-07-01T08:00:00-02:00")
+	entry.SetPublished(published)
+	feed.AddEntry(entry)
+
+	ref := `<item>
+  <title>Entry &lt;1&gt;</title>
+  <link>https://example.com/1</link>
+  <description>Fish &amp;amp; chips</description>
+  <content:encoded>&lt;p&gt;Hello&lt;/p&gt;</content:encoded>
+  <dc:creator>John Doe</dc:creator>
+  <category domain="https://example.com/tags">food</category>
+  <enclosure url="https://example.com/1.mp3" length="1337" type="audio/mpeg"></enclosure>
+  <guid isPermaLink="false">urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</guid>
+  <pubDate>Fri, 01 Jul 2022 08:00:00 -0200</pubDate>
+</item>`
+
+	items := FromAtom(feed).Channel.Items
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+	out, err := xmlMarshalIndent(items[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, out, ref)
+}
+
+func TestFromAtom_PermalinkGuid(t *testing.T) {
+	feed := makeTestFeed(t)
+	entry := makeTestEntry(t)
+	entry.Id = "https://example.com/1"
+	entry.AddLink("https://example.com/1", atom.RelAlternate)
+	entry.Authors = []atom.AtomAuthor{atom.AtomAuthor{Name: "Jane Doe"}}
+	feed.AddEntry(entry)
+
+	item := FromAtom(feed).Channel.Items[0]
+	if !item.Guid.IsPermaLink {
+		t.Error("Expected guid matching the item link to be a permalink")
+	}
+	if len(item.Creators) != 1 || item.Creators[0] != "Jane Doe" {
+		t.Errorf("Expected entry authors to take precedence, got %v", item.Creators)
+	}
+	if item.PubDate == nil || !time.Time(*item.PubDate).Equal(time.Time(entry.Updated)) {
+		t.Error("Expected pubDate to fall back to the entry updated date")
+	}
+}
+
+func TestFromAtom_ChannelLinkFallback(t *testing.T) {
+	feed := makeTestFeed(t)
+	feed.Id = "urn:uuid:60a76c80-d399-11d9-b93C-0003939e0af6"
+	feed.Generator = atom.AtomGenerator{Text: "Example Toolkit"}
+
+	channel := FromAtom(feed).Channel
+	assertEqual(t, channel.Link, "")
+	assertEqual(t, channel.Generator, "Example Toolkit")
+
+	feed.AddLink("https://example.com/feed", atom.RelSelf)
+	assertEqual(t, FromAtom(feed).Channel.Link, "https://example.com/feed")
+}
+
+func xmlMarshalIndent(v interface{}) (string, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	return string(out), err
+}
@@ -0,0 +1,27 @@
+package rss
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Encode writes the document to w as it is marshaled.
+func (rss RSS) Encode(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(rss); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func (rss RSS) String() string {
+	var out strings.Builder
+	// silently ignore errors
+	rss.Encode(&out)
+	return out.String()
+}
@@ -8,7 +8,9 @@ import (
 	h "github.com/bossley9/feedme/pkg/handlers"
 )
 
-func New(domain string, port string, certFile string, keyFile string) error {
+func New(domain string, port string, certFile string, keyFile string, validation h.ValidationMode) error {
+	h.SetValidationMode(validation)
+	h.SetBaseURL(getBaseURL(domain, port, certFile, keyFile))
 	r := h.SetupRouter()
 	http.Handle("/", r)
 
@@ -26,3 +28,20 @@ func New(domain string, port string, certFile string, keyFile string) error {
 		return srv.ListenAndServe()
 	}
 }
+
+// getBaseURL returns the scheme and host the server is reached with, leaving
+// out the default port of the scheme.
+func getBaseURL(domain string, port string, certFile string, keyFile string) (string, string) {
+	scheme, defaultPort := "http", "80"
+	if len(certFile) > 0 && len(keyFile) > 0 {
+		scheme, defaultPort = "https", "443"
+	}
+	if len(domain) == 0 {
+		// servers listening on every interface are reached locally
+		domain = "localhost"
+	}
+	if port == defaultPort {
+		return scheme, domain
+	}
+	return scheme, domain + ":" + port
+}